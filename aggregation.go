@@ -0,0 +1,262 @@
+package blackvice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/spanner"
+	sppb "google.golang.org/genproto/googleapis/spanner/v1"
+)
+
+type Aggregate struct {
+	fn       string
+	column   string
+	distinct bool
+	alias    string
+}
+
+func Sum(col string) Aggregate {
+	return Aggregate{fn: "SUM", column: col}
+}
+
+func Avg(col string) Aggregate {
+	return Aggregate{fn: "AVG", column: col}
+}
+
+func Min(col string) Aggregate {
+	return Aggregate{fn: "MIN", column: col}
+}
+
+func Max(col string) Aggregate {
+	return Aggregate{fn: "MAX", column: col}
+}
+
+func Count(col string) Aggregate {
+	return Aggregate{fn: "COUNT", column: col}
+}
+
+func CountAll() Aggregate {
+	return Aggregate{fn: "COUNT"}
+}
+
+func CountDistinct(col string) Aggregate {
+	return Aggregate{fn: "COUNT", column: col, distinct: true}
+}
+
+func (a Aggregate) As(alias string) Aggregate {
+	a.alias = alias
+	return a
+}
+
+// Name is the result column of the aggregate, e.g. sum_Age for Sum("Age").
+func (a Aggregate) Name() string {
+	if a.alias != "" {
+		return a.alias
+	}
+	if a.column == "" {
+		return strings.ToLower(a.fn)
+	}
+	if a.distinct {
+		return paramName(strings.ToLower(a.fn) + "_distinct_" + a.column)
+	}
+	return paramName(strings.ToLower(a.fn) + "_" + a.column)
+}
+
+func (a Aggregate) build() string {
+	arg := "*"
+	if a.column != "" {
+		arg = quote(a.column)
+	}
+	if a.distinct {
+		arg = "DISTINCT " + arg
+	}
+	return fmt.Sprintf("%s(%s) AS %s", a.fn, arg, quote(a.Name()))
+}
+
+type Aggregation struct {
+	qc      *QueryContext
+	aggs    []Aggregate
+	groupBy []string
+	having  []Condition
+}
+
+func (b *QueryContext) Aggregate(aggs ...Aggregate) *Aggregation {
+	return &Aggregation{qc: b, aggs: aggs}
+}
+
+func (a *Aggregation) GroupBy(cols ...string) *Aggregation {
+	r := *a
+	r.groupBy = append(append([]string{}, a.groupBy...), cols...)
+	return &r
+}
+
+// Having conditions can refer to group columns and aggregate names.
+func (a *Aggregation) Having(conds ...Condition) *Aggregation {
+	r := *a
+	r.having = append(append([]Condition{}, a.having...), conds...)
+	return &r
+}
+
+func (a *Aggregation) SQL() string {
+	query, _ := a.build()
+	return query
+}
+
+func (a *Aggregation) build() (string, map[string]interface{}) {
+	binder := newBinder()
+
+	var selects []string
+	var groups []string
+	for _, col := range a.groupBy {
+		selects = append(selects, quote(col))
+		groups = append(groups, quote(col))
+	}
+	for _, agg := range a.aggs {
+		selects = append(selects, agg.build())
+	}
+
+	where := ""
+	if !a.qc.where().IsEmpty() {
+		where = "WHERE " + a.qc.where().expr(binder)
+	}
+
+	groupBy := ""
+	if len(groups) != 0 {
+		groupBy = "GROUP BY " + strings.Join(groups, ", ")
+	}
+
+	having := ""
+	if len(a.having) != 0 {
+		having = "HAVING " + And(a.having...).build(binder)
+	}
+
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s %s %s %s %s %s",
+		strings.Join(selects, ", "),
+		a.qc.from(),
+		where,
+		groupBy,
+		having,
+		a.qc.orderBuilder.Build(),
+		a.qc.limitClause(),
+	)
+
+	return query, binder.params
+}
+
+// Rows returns one map per group keyed by group column and aggregate name.
+// NULL results are returned as nil.
+func (a *Aggregation) Rows(ctx context.Context) ([]map[string]interface{}, error) {
+	if a.qc.err != nil {
+		return nil, a.qc.err
+	}
+
+	query, params := a.build()
+	res := []map[string]interface{}{}
+
+	err := a.qc.query(ctx, query, params, func(row *spanner.Row) error {
+		values := map[string]interface{}{}
+		for i, name := range row.ColumnNames() {
+			val, err := columnValue(row, i)
+			if err != nil {
+				return err
+			}
+			values[name] = val
+		}
+		res = append(res, values)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Scalar decodes the first column of the single result row into dest, e.g.
+// Aggregate(Sum("Age")).Scalar(ctx, &sum) with sum as int64 or spanner.NullInt64.
+func (a *Aggregation) Scalar(ctx context.Context, dest interface{}) error {
+	if a.qc.err != nil {
+		return a.qc.err
+	}
+
+	query, params := a.build()
+	found := 0
+
+	err := a.qc.query(ctx, query, params, func(row *spanner.Row) error {
+		found++
+		if found > 1 {
+			return errMultipleRowsFound(a.qc.Table(), query)
+		}
+		return row.Column(0, dest)
+	})
+	if err != nil {
+		return err
+	}
+	if found == 0 {
+		return errRowNotFound(a.qc.Table(), query)
+	}
+
+	return nil
+}
+
+func columnValue(row *spanner.Row, i int) (interface{}, error) {
+	var gcv spanner.GenericColumnValue
+	if err := row.Column(i, &gcv); err != nil {
+		return nil, err
+	}
+
+	switch gcv.Type.Code {
+	case sppb.TypeCode_INT64:
+		var v spanner.NullInt64
+		if err := gcv.Decode(&v); err != nil || !v.Valid {
+			return nil, err
+		}
+		return v.Int64, nil
+	case sppb.TypeCode_FLOAT64:
+		var v spanner.NullFloat64
+		if err := gcv.Decode(&v); err != nil || !v.Valid {
+			return nil, err
+		}
+		return v.Float64, nil
+	case sppb.TypeCode_NUMERIC:
+		var v spanner.NullNumeric
+		if err := gcv.Decode(&v); err != nil || !v.Valid {
+			return nil, err
+		}
+		return v.Numeric, nil
+	case sppb.TypeCode_STRING:
+		var v spanner.NullString
+		if err := gcv.Decode(&v); err != nil || !v.Valid {
+			return nil, err
+		}
+		return v.StringVal, nil
+	case sppb.TypeCode_BOOL:
+		var v spanner.NullBool
+		if err := gcv.Decode(&v); err != nil || !v.Valid {
+			return nil, err
+		}
+		return v.Bool, nil
+	case sppb.TypeCode_TIMESTAMP:
+		var v spanner.NullTime
+		if err := gcv.Decode(&v); err != nil || !v.Valid {
+			return nil, err
+		}
+		return v.Time, nil
+	case sppb.TypeCode_DATE:
+		var v spanner.NullDate
+		if err := gcv.Decode(&v); err != nil || !v.Valid {
+			return nil, err
+		}
+		return v.Date, nil
+	case sppb.TypeCode_BYTES:
+		var v []byte
+		if err := gcv.Decode(&v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+
+	return gcv, nil
+}
@@ -0,0 +1,295 @@
+package blackvice
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+type AssociationKind int
+
+const (
+	HasManyAssociation AssociationKind = iota
+	HasOneAssociation
+	BelongsToAssociation
+)
+
+// preloadBatchSize is the most keys bound to one preload query.
+const preloadBatchSize = 1000
+
+// Association relates a model to the rows of another table. Field is the
+// struct field receiving them, a slice of the related model for has-many and
+// a pointer or value for has-one and belongs-to. It should be tagged
+// `spanner:"-"`.
+type Association struct {
+	Kind  AssociationKind
+	Field string
+	Model Model
+	// ForeignKey is the column of the related table for has-many and has-one,
+	// and of the model itself for belongs-to.
+	ForeignKey string
+	// References is the column the foreign key refers to, in the model itself
+	// for has-many and has-one, and in the related table for belongs-to.
+	References string
+}
+
+// Associator is implemented by models with associations to preload, e.g.
+//
+//	func (u *User) Associations() []blackvice.Association {
+//		return []blackvice.Association{
+//			blackvice.HasMany("Posts", &Post{}, "UserId", "UserId"),
+//		}
+//	}
+type Associator interface {
+	Associations() []Association
+}
+
+func HasMany(field string, model Model, foreignKey, references string) Association {
+	return Association{Kind: HasManyAssociation, Field: field, Model: model, ForeignKey: foreignKey, References: references}
+}
+
+// HasOne sets the field to the first matching row in the default scope order
+// of the related model, then in primary key order, when several rows match.
+func HasOne(field string, model Model, foreignKey, references string) Association {
+	return Association{Kind: HasOneAssociation, Field: field, Model: model, ForeignKey: foreignKey, References: references}
+}
+
+func BelongsTo(field string, model Model, foreignKey, references string) Association {
+	return Association{Kind: BelongsToAssociation, Field: field, Model: model, ForeignKey: foreignKey, References: references}
+}
+
+// Preload loads the associations named by their fields after All, FindOne
+// and Paginate, with one query per association and preloadBatchSize keys,
+// each in a new single-use transaction for relations of a DB. Nested
+// associations are separated by dots, e.g. Preload("Posts.User"). Each and
+// Iter do not preload.
+func (b *QueryContext) Preload(names ...string) Relation {
+	r := b.clone()
+	r.preloads = append(append([]string{}, b.preloads...), names...)
+	for _, name := range names {
+		field := strings.SplitN(name, ".", 2)[0]
+		if _, err := findAssociation(b.model, field); err != nil && r.err == nil {
+			r.err = err
+		}
+	}
+	return r
+}
+
+func (b *QueryContext) preload(ctx context.Context, models []Model) error {
+	for _, name := range b.preloads {
+		if err := b.preloadPath(ctx, models, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *QueryContext) preloadPath(ctx context.Context, models []Model, path string) error {
+	if len(models) == 0 {
+		return nil
+	}
+
+	parts := strings.SplitN(path, ".", 2)
+	assoc, err := findAssociation(models[0], parts[0])
+	if err != nil {
+		return err
+	}
+
+	related, err := b.loadAssociation(ctx, models, assoc)
+	if err != nil {
+		return err
+	}
+
+	if len(parts) == 2 {
+		return b.preloadPath(ctx, related, parts[1])
+	}
+	return nil
+}
+
+// loadAssociation queries the related rows of models and attaches them,
+// returning the related rows.
+func (b *QueryContext) loadAssociation(ctx context.Context, models []Model, assoc Association) ([]Model, error) {
+	ownKey, relatedKey := assoc.References, assoc.ForeignKey
+	if assoc.Kind == BelongsToAssociation {
+		ownKey, relatedKey = assoc.ForeignKey, assoc.References
+	}
+
+	values, err := associationKeys(models, ownKey)
+	if err != nil {
+		return nil, err
+	}
+	if values.Len() == 0 {
+		return []Model{}, attachAssociation(models, assoc, ownKey, relatedKey, nil)
+	}
+
+	base := newQueryContext(assoc.Model, b.txFn)
+	if b.tenant != nil {
+		base = base.withTenant(b.tenant)
+	}
+
+	related := []Model{}
+	for i := 0; i < values.Len(); i += preloadBatchSize {
+		j := i + preloadBatchSize
+		if j > values.Len() {
+			j = values.Len()
+		}
+
+		q := base.Filter(In(relatedKey, values.Slice(i, j).Interface())).(*QueryContext)
+		rows, err := q.pageOrder().All(ctx)
+		if err != nil {
+			return nil, err
+		}
+		related = append(related, rows...)
+	}
+
+	return related, attachAssociation(models, assoc, ownKey, relatedKey, related)
+}
+
+func findAssociation(model Model, field string) (Association, error) {
+	if a, ok := model.(Associator); ok {
+		for _, assoc := range a.Associations() {
+			if assoc.Field == field {
+				return assoc, nil
+			}
+		}
+	}
+	return Association{}, errInvalidAssociation(fmt.Sprintf("%s has no association %s", model.Table(), field))
+}
+
+// associationKeys returns the distinct non NULL values of col as a typed
+// slice, so it can be bound as an ARRAY parameter.
+func associationKeys(models []Model, col string) (reflect.Value, error) {
+	var values reflect.Value
+	seen := map[interface{}]bool{}
+
+	for _, model := range models {
+		val, ok := associationKey(model, col)
+		if !ok {
+			continue
+		}
+		if !values.IsValid() {
+			values = reflect.MakeSlice(reflect.SliceOf(val.Type()), 0, len(models))
+		}
+		if val.Type() != values.Type().Elem() {
+			return reflect.Value{}, errInvalidAssociation(fmt.Sprintf("%s has mixed types for %s", model.Table(), col))
+		}
+
+		key, err := associationMapKey(model, col, val)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if !seen[key] {
+			seen[key] = true
+			values = reflect.Append(values, val)
+		}
+	}
+
+	if !values.IsValid() {
+		return reflect.ValueOf([]string{}), nil
+	}
+	return values, nil
+}
+
+// associationKey returns the value of col with spanner.Null* unwrapped, and
+// false when it is NULL.
+func associationKey(model Model, col string) (reflect.Value, bool) {
+	raw, ok := model.Params()[col]
+	if !ok || raw == nil {
+		return reflect.Value{}, false
+	}
+	val, null := fieldValue(reflect.ValueOf(raw))
+	if null {
+		return reflect.Value{}, false
+	}
+	return val, true
+}
+
+// associationMapKey returns the value of a key column to match related rows
+// by, which compares equal across integer types.
+func associationMapKey(model Model, col string, val reflect.Value) (interface{}, error) {
+	key, _ := keyValue(val.Interface())
+	if !reflect.TypeOf(key).Comparable() {
+		return nil, errInvalidAssociation(fmt.Sprintf("%s.%s cannot be used as a key", model.Table(), col))
+	}
+	return key, nil
+}
+
+func attachAssociation(models []Model, assoc Association, ownKey, relatedKey string, related []Model) error {
+	byKey := map[interface{}][]Model{}
+	for _, r := range related {
+		if val, ok := associationKey(r, relatedKey); ok {
+			key, err := associationMapKey(r, relatedKey, val)
+			if err != nil {
+				return err
+			}
+			byKey[key] = append(byKey[key], r)
+		}
+	}
+
+	for _, model := range models {
+		var matched []Model
+		if val, ok := associationKey(model, ownKey); ok {
+			key, err := associationMapKey(model, ownKey, val)
+			if err != nil {
+				return err
+			}
+			matched = byKey[key]
+		}
+		if err := setAssociation(model, assoc, matched); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setAssociation(model Model, assoc Association, matched []Model) error {
+	rv := reflect.ValueOf(model)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return errInvalidAssociation(fmt.Sprintf("cannot set %s of %T", assoc.Field, model))
+	}
+
+	field := rv.Elem().FieldByName(assoc.Field)
+	if !field.IsValid() || !field.CanSet() {
+		return errInvalidAssociation(fmt.Sprintf("%T has no field %s", model, assoc.Field))
+	}
+
+	relatedType := reflect.TypeOf(assoc.Model)
+	value := func(m Model, rt reflect.Type) (reflect.Value, error) {
+		v := reflect.ValueOf(m)
+		if rt == relatedType {
+			return v, nil
+		}
+		if rt == relatedType.Elem() {
+			return v.Elem(), nil
+		}
+		return reflect.Value{}, errInvalidAssociation(fmt.Sprintf("%T.%s cannot hold %v", model, assoc.Field, relatedType))
+	}
+
+	if assoc.Kind == HasManyAssociation {
+		if field.Kind() != reflect.Slice {
+			return errInvalidAssociation(fmt.Sprintf("%T.%s must be a slice", model, assoc.Field))
+		}
+		slice := reflect.MakeSlice(field.Type(), 0, len(matched))
+		for _, m := range matched {
+			v, err := value(m, field.Type().Elem())
+			if err != nil {
+				return err
+			}
+			slice = reflect.Append(slice, v)
+		}
+		field.Set(slice)
+		return nil
+	}
+
+	if len(matched) == 0 {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+	v, err := value(matched[0], field.Type())
+	if err != nil {
+		return err
+	}
+	field.Set(v)
+	return nil
+}
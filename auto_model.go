@@ -0,0 +1,300 @@
+package blackvice
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"cloud.google.com/go/spanner"
+	"github.com/pkg/errors"
+)
+
+// AutoModel implements Model from struct tags. Embed it as the first field
+// of a struct with its own type as the type parameter:
+//
+//	type User struct {
+//		blackvice.AutoModel[User] `spanner:"-" blackvice:"table=users"`
+//		UserId string `blackvice:"pk"`
+//		Name   string
+//	}
+//
+// Column names follow the spanner tag like spanner.Row.ToStruct, and
+// `blackvice:"pk,order=N"` marks primary key columns in key order, numbered
+// from 1 without gaps, and `blackvice:"version"` the lock version column
+// (see Versioned). `blackvice:"created_at"` and `blackvice:"updated_at"` mark
+// the columns set to the commit timestamp (see Timestamped), and
+// `blackvice:"deleted_at"` the soft delete column (see SoftDeletable).
+//
+// Invalid tags fail Register and every query and write of the model.
+type AutoModel[T any] struct{}
+
+func (m *AutoModel[T]) Table() string {
+	return m.meta().table
+}
+
+func (m *AutoModel[T]) Params() map[string]interface{} {
+	rv, meta := m.self(), m.meta()
+
+	params := map[string]interface{}{}
+	for _, f := range meta.fields {
+		params[f.column] = rv.FieldByIndex(f.index).Interface()
+	}
+	return params
+}
+
+func (m *AutoModel[T]) SpannerKey() spanner.Key {
+	rv, meta := m.self(), m.meta()
+
+	key := spanner.Key{}
+	for _, f := range meta.pks {
+		key = append(key, rv.FieldByIndex(f.index).Interface())
+	}
+	return key
+}
+
+func (m *AutoModel[T]) PrimaryKeys() map[string]interface{} {
+	rv, meta := m.self(), m.meta()
+
+	params := map[string]interface{}{}
+	for _, f := range meta.pks {
+		params[f.column] = rv.FieldByIndex(f.index).Interface()
+	}
+	return params
+}
+
+func (m *AutoModel[T]) Columns() []string {
+	var columns []string
+	for _, f := range m.meta().fields {
+		columns = append(columns, f.column)
+	}
+	return columns
+}
+
+func (m *AutoModel[T]) PrimaryKeyColumns() []string {
+	var columns []string
+	for _, f := range m.meta().pks {
+		columns = append(columns, f.column)
+	}
+	return columns
+}
+
+func (m *AutoModel[T]) VersionColumn() string {
+	return m.meta().version
+}
+
+func (m *AutoModel[T]) TimestampColumns() (createdAt, updatedAt string) {
+	meta := m.meta()
+	return meta.createdAt, meta.updatedAt
+}
+
+func (m *AutoModel[T]) SoftDeleteColumn() string {
+	return m.meta().deletedAt
+}
+
+// meta returns an empty modelMeta for invalid tags, which relations,
+// transactions and mutations refuse with the error of modelError.
+func (m *AutoModel[T]) meta() *modelMeta {
+	rt := reflect.TypeOf((*T)(nil)).Elem()
+	meta, err := metaOf(rt)
+	if err != nil {
+		return &modelMeta{table: rt.Name()}
+	}
+	return meta
+}
+
+func (m *AutoModel[T]) metaError() error {
+	_, err := metaOf(reflect.TypeOf((*T)(nil)).Elem())
+	return err
+}
+
+// self returns the struct embedding m, which starts at the same address
+// since metaOf only accepts AutoModel as the first field.
+func (m *AutoModel[T]) self() reflect.Value {
+	return reflect.NewAt(reflect.TypeOf((*T)(nil)).Elem(), unsafe.Pointer(m)).Elem()
+}
+
+// Register validates the tags of an AutoModel struct up front, so mistakes
+// surface at startup instead of as an error of the first query or write.
+func Register(model interface{}) error {
+	rt := reflect.TypeOf(model)
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	_, err := metaOf(rt)
+	return err
+}
+
+// modelError returns the error of an AutoModel with invalid tags.
+func modelError(model Model) error {
+	if m, ok := model.(interface{ metaError() error }); ok {
+		if err := m.metaError(); err != nil {
+			return errors.Wrapf(err, "invalid AutoModel %s", model.Table())
+		}
+	}
+	return nil
+}
+
+type modelMeta struct {
+	table     string
+	fields    []fieldMeta
+	pks       []fieldMeta
+	version   string
+	createdAt string
+	updatedAt string
+	deletedAt string
+}
+
+type fieldMeta struct {
+	column string
+	index  []int
+	order  int
+}
+
+var metaCache sync.Map
+
+func metaOf(rt reflect.Type) (*modelMeta, error) {
+	if cached, ok := metaCache.Load(rt); ok {
+		return cached.(*modelMeta), nil
+	}
+
+	meta, err := buildMeta(rt)
+	if err != nil {
+		return nil, err
+	}
+
+	cached, _ := metaCache.LoadOrStore(rt, meta)
+	return cached.(*modelMeta), nil
+}
+
+func buildMeta(rt reflect.Type) (*modelMeta, error) {
+	if rt.Kind() != reflect.Struct {
+		return nil, errors.Errorf("%v is not a struct", rt)
+	}
+
+	meta := &modelMeta{table: rt.Name()}
+	embedded := false
+
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		tag := parseTag(sf.Tag.Get("blackvice"))
+
+		if sf.Anonymous && sf.Type.PkgPath() == autoModelPkg {
+			// ChangeTracker and the like are not columns
+			if !strings.HasPrefix(sf.Type.Name(), "AutoModel[") {
+				continue
+			}
+			if sf.Offset != 0 {
+				return nil, errors.Errorf("%v: AutoModel must be the first field", rt)
+			}
+			if table, ok := tag["table"]; ok {
+				meta.table = table
+			}
+			embedded = true
+			continue
+		}
+
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		column := sf.Name
+		if name := sf.Tag.Get("spanner"); name == "-" {
+			continue
+		} else if name != "" {
+			column = name
+		}
+
+		f := fieldMeta{column: column, index: sf.Index}
+		meta.fields = append(meta.fields, f)
+
+		if _, ok := tag["pk"]; ok {
+			if order, ok := tag["order"]; ok {
+				n, err := strconv.Atoi(order)
+				if err != nil || n < 1 {
+					return nil, errors.Errorf("%v.%s: invalid primary key order %q", rt, sf.Name, order)
+				}
+				f.order = n
+			}
+			meta.pks = append(meta.pks, f)
+		}
+
+		if _, ok := tag["version"]; ok {
+			if meta.version != "" {
+				return nil, errors.Errorf("%v has more than one version field", rt)
+			}
+			meta.version = column
+		}
+		if _, ok := tag["created_at"]; ok {
+			meta.createdAt = column
+		}
+		if _, ok := tag["updated_at"]; ok {
+			meta.updatedAt = column
+		}
+		if _, ok := tag["deleted_at"]; ok {
+			meta.deletedAt = column
+		}
+	}
+
+	if !embedded {
+		return nil, errors.Errorf("%v does not embed AutoModel", rt)
+	}
+	if len(meta.pks) == 0 {
+		return nil, errors.Errorf("%v has no primary key field", rt)
+	}
+
+	sort.SliceStable(meta.pks, func(i, j int) bool {
+		return meta.pks[i].order < meta.pks[j].order
+	})
+	if err := checkKeyOrder(rt, meta.pks); err != nil {
+		return nil, err
+	}
+
+	return meta, nil
+}
+
+var autoModelPkg = reflect.TypeOf(AutoModel[struct{}]{}).PkgPath()
+
+// parseTag parses `pk,order=1` into {"pk": "", "order": "1"}.
+func parseTag(tag string) map[string]string {
+	opts := map[string]string{}
+	for _, opt := range strings.Split(tag, ",") {
+		opt = strings.TrimSpace(opt)
+		if opt == "" {
+			continue
+		}
+		kv := strings.SplitN(opt, "=", 2)
+		if len(kv) == 2 {
+			opts[kv[0]] = kv[1]
+		} else {
+			opts[kv[0]] = ""
+		}
+	}
+	return opts
+}
+
+// checkKeyOrder requires the orders of primary key fields to be 1 to n when
+// any is given, so a gap or duplicate does not silently change the key.
+func checkKeyOrder(rt reflect.Type, pks []fieldMeta) error {
+	ordered := 0
+	for _, f := range pks {
+		if f.order != 0 {
+			ordered++
+		}
+	}
+	if ordered == 0 {
+		return nil
+	}
+	if ordered != len(pks) {
+		return errors.Errorf("%v: every primary key field needs an order when one has", rt)
+	}
+
+	for i, f := range pks {
+		if f.order != i+1 {
+			return errors.Errorf("%v: primary key orders must be 1 to %d without gaps or duplicates", rt, len(pks))
+		}
+	}
+	return nil
+}
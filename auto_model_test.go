@@ -0,0 +1,49 @@
+package blackvice_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yuemori/blackvice"
+)
+
+type gappedKey struct {
+	blackvice.AutoModel[gappedKey] `spanner:"-" blackvice:"table=gapped"`
+
+	A string `blackvice:"pk,order=1"`
+	B string `blackvice:"pk,order=3"`
+}
+
+type duplicatedKey struct {
+	blackvice.AutoModel[duplicatedKey] `spanner:"-" blackvice:"table=duplicated"`
+
+	A string `blackvice:"pk,order=1"`
+	B string `blackvice:"pk,order=1"`
+}
+
+type partiallyOrderedKey struct {
+	blackvice.AutoModel[partiallyOrderedKey] `spanner:"-" blackvice:"table=partial"`
+
+	A string `blackvice:"pk"`
+	B string `blackvice:"pk,order=2"`
+}
+
+type malformedOrder struct {
+	blackvice.AutoModel[malformedOrder] `spanner:"-" blackvice:"table=malformed"`
+
+	A string `blackvice:"pk,order=first"`
+}
+
+func TestRegisterInvalid(t *testing.T) {
+	models := []interface{}{&gappedKey{}, &duplicatedKey{}, &partiallyOrderedKey{}, &malformedOrder{}}
+	for _, model := range models {
+		if err := blackvice.Register(model); err == nil {
+			t.Errorf("Register %T must fail", model)
+		}
+	}
+
+	rel := blackvice.NewQueryContext(&malformedOrder{}, nil)
+	if _, err := rel.Count(context.Background()); err == nil {
+		t.Fatalf("Relation of an invalid AutoModel must fail")
+	}
+}
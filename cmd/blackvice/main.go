@@ -0,0 +1,90 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/yuemori/blackvice/gen"
+)
+
+const usage = `usage: blackvice <command> [arguments]
+
+commands:
+  gen    generate Model implementations from Spanner DDL
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "gen":
+		if err := runGen(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "blackvice gen: %v\n", err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func runGen(args []string) error {
+	fs := flag.NewFlagSet("gen", flag.ExitOnError)
+	pkg := fs.String("package", "models", "package name of the generated file")
+	out := fs.String("o", "", "output file (default stdout)")
+	structs := structNames{}
+	fs.Var(structs, "struct", "struct name of a table as table=Name, may be repeated")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: blackvice gen [-package name] [-struct table=Name]... [-o file] schema.sql")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	ddl, err := ioutil.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	src, err := gen.Generate(string(ddl), gen.Options{Package: *pkg, Structs: structs})
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return ioutil.WriteFile(*out, src, 0644)
+}
+
+// structNames collects the table=Name values of the -struct flag.
+type structNames map[string]string
+
+func (s structNames) String() string {
+	pairs := make([]string, 0, len(s))
+	for table, name := range s {
+		pairs = append(pairs, table+"="+name)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (s structNames) Set(v string) error {
+	table, name, ok := strings.Cut(v, "=")
+	if !ok || table == "" || name == "" {
+		return fmt.Errorf("invalid -struct %q, want table=Name", v)
+	}
+	s[table] = name
+	return nil
+}
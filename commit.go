@@ -0,0 +1,128 @@
+package blackvice
+
+import (
+	"time"
+
+	"cloud.google.com/go/spanner"
+)
+
+type CommitOptions struct {
+	// ReturnCommitStats requests commit statistics such as MutationCount.
+	ReturnCommitStats bool
+}
+
+type CommitResult struct {
+	Timestamp time.Time
+	// MutationCount is only set when CommitOptions.ReturnCommitStats is set.
+	MutationCount int64
+}
+
+func (o CommitOptions) transactionOptions() spanner.TransactionOptions {
+	return spanner.TransactionOptions{
+		CommitOptions: spanner.CommitOptions{ReturnCommitStats: o.ReturnCommitStats},
+	}
+}
+
+func newCommitResult(resp spanner.CommitResponse) CommitResult {
+	return CommitResult{
+		Timestamp:     resp.CommitTs,
+		MutationCount: resp.CommitStats.GetMutationCount(),
+	}
+}
+
+// commitEffects are the changes to written models which wait for the commit,
+// so an aborted and retried transaction writes the same values again and a
+// failed one leaves the models as they were.
+type commitEffects struct {
+	written []written
+	bumps   []versionBump
+	stamps  []stamp
+}
+
+// versionBump is the lock version a model gets once its update is committed.
+type versionBump struct {
+	model Model
+	col   string
+	next  interface{}
+}
+
+// written is a model with the params it was written with, which become its
+// change tracking snapshot once committed.
+type written struct {
+	model  Model
+	params map[string]interface{}
+}
+
+func (e *commitEffects) write(model Model) {
+	e.written = append(e.written, written{model: model, params: model.Params()})
+}
+
+func (e *commitEffects) bump(model Model, col string, next interface{}) {
+	e.bumps = append(e.bumps, versionBump{model: model, col: col, next: next})
+}
+
+// version returns the lock version of model in the transaction, which is
+// the one of its last pending update, if any.
+func (e *commitEffects) version(model Model, col string) interface{} {
+	for i := len(e.bumps) - 1; i >= 0; i-- {
+		if e.bumps[i].model == model {
+			return e.bumps[i].next
+		}
+	}
+	return model.Params()[col]
+}
+
+// stamp records the columns of model to set to the commit timestamp.
+func (e *commitEffects) stamp(model Model, cols ...string) {
+	s := stamp{model: model}
+	for _, col := range cols {
+		if col != "" {
+			s.cols = append(s.cols, col)
+		}
+	}
+	if len(s.cols) > 0 {
+		e.stamps = append(e.stamps, s)
+	}
+}
+
+// apply stores the bumped versions and ts into the models and snapshots the
+// written models with the values they were written with.
+func (e *commitEffects) apply(ts time.Time) error {
+	for _, v := range e.bumps {
+		if err := setColumn(v.model, v.col, v.next); err != nil {
+			return err
+		}
+	}
+
+	for _, s := range e.stamps {
+		for _, col := range s.cols {
+			if err := setTimestamp(s.model, col, ts); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, w := range e.written {
+		t, ok := w.model.(tracker)
+		if !ok {
+			continue
+		}
+		current := w.model.Params()
+		for _, v := range e.bumps {
+			if v.model == w.model {
+				w.params[v.col] = current[v.col]
+			}
+		}
+		for _, s := range e.stamps {
+			if s.model != w.model {
+				continue
+			}
+			for _, col := range s.cols {
+				w.params[col] = current[col]
+			}
+		}
+		t.trackOriginal(w.params)
+	}
+
+	return nil
+}
@@ -0,0 +1,246 @@
+package blackvice
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type Condition interface {
+	build(b *binder) string
+}
+
+func Eq(col string, val interface{}) Condition {
+	return compareCond{col: col, op: "=", val: val}
+}
+
+func Ne(col string, val interface{}) Condition {
+	return compareCond{col: col, op: "!=", val: val}
+}
+
+func Gt(col string, val interface{}) Condition {
+	return compareCond{col: col, op: ">", val: val}
+}
+
+func Gte(col string, val interface{}) Condition {
+	return compareCond{col: col, op: ">=", val: val}
+}
+
+func Lt(col string, val interface{}) Condition {
+	return compareCond{col: col, op: "<", val: val}
+}
+
+func Lte(col string, val interface{}) Condition {
+	return compareCond{col: col, op: "<=", val: val}
+}
+
+func Like(col string, pattern string) Condition {
+	return compareCond{col: col, op: "LIKE", val: pattern}
+}
+
+// values must be a slice, it is bound as an ARRAY parameter.
+func In(col string, values interface{}) Condition {
+	return inCond{col: col, values: values}
+}
+
+func NotIn(col string, values interface{}) Condition {
+	return inCond{col: col, values: values, not: true}
+}
+
+func StartsWith(col string, prefix string) Condition {
+	return startsWithCond{col: col, prefix: prefix}
+}
+
+func IsNull(col string) Condition {
+	return nullCond{col: col}
+}
+
+func IsNotNull(col string) Condition {
+	return nullCond{col: col, not: true}
+}
+
+func Between(col string, from, to interface{}) Condition {
+	return betweenCond{col: col, from: from, to: to}
+}
+
+func And(conds ...Condition) Condition {
+	return groupCond{op: "AND", conds: conds}
+}
+
+func Or(conds ...Condition) Condition {
+	return groupCond{op: "OR", conds: conds}
+}
+
+// Not(nil) is nil, which conditions and Filter ignore.
+func Not(cond Condition) Condition {
+	if cond == nil {
+		return nil
+	}
+	return notCond{cond: cond}
+}
+
+type compareCond struct {
+	col string
+	op  string
+	val interface{}
+}
+
+func (c compareCond) build(b *binder) string {
+	return fmt.Sprintf("%s %s %s", quote(c.col), c.op, b.bind(c.col, c.val))
+}
+
+type inCond struct {
+	col    string
+	values interface{}
+	not    bool
+}
+
+func (c inCond) build(b *binder) string {
+	op := "IN"
+	if c.not {
+		op = "NOT IN"
+	}
+	return fmt.Sprintf("%s %s UNNEST(%s)", quote(c.col), op, b.bind(c.col, c.values))
+}
+
+type startsWithCond struct {
+	col    string
+	prefix string
+}
+
+func (c startsWithCond) build(b *binder) string {
+	return fmt.Sprintf("STARTS_WITH(%s, %s)", quote(c.col), b.bind(c.col, c.prefix))
+}
+
+type nullCond struct {
+	col string
+	not bool
+}
+
+func (c nullCond) build(b *binder) string {
+	if c.not {
+		return quote(c.col) + " IS NOT NULL"
+	}
+	return quote(c.col) + " IS NULL"
+}
+
+type betweenCond struct {
+	col      string
+	from, to interface{}
+}
+
+func (c betweenCond) build(b *binder) string {
+	return fmt.Sprintf("%s BETWEEN %s AND %s", quote(c.col), b.bind(c.col, c.from), b.bind(c.col, c.to))
+}
+
+type groupCond struct {
+	op    string
+	conds []Condition
+}
+
+func (c groupCond) build(b *binder) string {
+	var exprs []string
+	for _, cond := range c.conds {
+		if cond == nil {
+			continue
+		}
+		exprs = append(exprs, cond.build(b))
+	}
+
+	switch len(exprs) {
+	case 0:
+		// an empty AND matches every row, an empty OR matches none
+		if c.op == "OR" {
+			return "FALSE"
+		}
+		return "TRUE"
+	case 1:
+		return exprs[0]
+	}
+
+	return "(" + strings.Join(exprs, " "+c.op+" ") + ")"
+}
+
+type notCond struct {
+	cond Condition
+}
+
+func (c notCond) build(b *binder) string {
+	if c.cond == nil {
+		return "TRUE"
+	}
+	return fmt.Sprintf("NOT (%s)", c.cond.build(b))
+}
+
+func (p WhereParam) build(b *binder) string {
+	cols := make([]string, 0, len(p))
+	for col := range p {
+		cols = append(cols, col)
+	}
+	sort.Strings(cols)
+
+	conds := make([]Condition, 0, len(cols))
+	for _, col := range cols {
+		val := p[col]
+		switch {
+		case val == nil:
+			conds = append(conds, IsNull(col))
+		case isSliceParam(val):
+			conds = append(conds, In(col, val))
+		default:
+			conds = append(conds, Eq(col, val))
+		}
+	}
+
+	return And(conds...).build(b)
+}
+
+func isSliceParam(val interface{}) bool {
+	rv := reflect.ValueOf(val)
+	if rv.Kind() != reflect.Slice {
+		return false
+	}
+	// []byte is bound as BYTES, not as an array
+	return rv.Type().Elem().Kind() != reflect.Uint8
+}
+
+type binder struct {
+	params map[string]interface{}
+}
+
+func newBinder() *binder {
+	return &binder{params: map[string]interface{}{}}
+}
+
+func (b *binder) bind(name string, val interface{}) string {
+	base := paramName(name)
+	key := base
+	for i := 1; ; i++ {
+		if _, ok := b.params[key]; !ok {
+			break
+		}
+		key = fmt.Sprintf("%s_%d", base, i)
+	}
+	b.params[key] = val
+
+	return placeholder(key)
+}
+
+func paramName(name string) string {
+	var sb strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune('_')
+		}
+	}
+
+	s := sb.String()
+	if s == "" || (s[0] >= '0' && s[0] <= '9') {
+		s = "p" + s
+	}
+	return s
+}
@@ -0,0 +1,34 @@
+package blackvice_test
+
+import (
+	"testing"
+
+	"github.com/yuemori/blackvice"
+)
+
+func TestConditionSQL(t *testing.T) {
+	tests := []struct {
+		conds    []blackvice.Condition
+		expected string
+	}{
+		{
+			[]blackvice.Condition{blackvice.Not(blackvice.Eq("Name", "test1"))},
+			"WHERE NOT (`Name` = @Name)",
+		},
+		{
+			[]blackvice.Condition{blackvice.Not(nil), blackvice.Gte("Age", 20)},
+			"WHERE `Age` >= @Age",
+		},
+		{
+			[]blackvice.Condition{blackvice.Or(blackvice.Eq("Age", 18), blackvice.Eq("Age", 20))},
+			"WHERE (`Age` = @Age OR `Age` = @Age_1)",
+		},
+	}
+
+	for _, tt := range tests {
+		where := blackvice.WhereBuilder{}.And(tt.conds...)
+		if sql := where.Build(); sql != tt.expected {
+			t.Errorf("Expected SQL is %s, but %s", tt.expected, sql)
+		}
+	}
+}
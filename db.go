@@ -10,36 +10,57 @@ type Reader interface {
 	Relation(Model) *QueryContext
 
 	Find(context.Context, Model) error
+	FindAll(ctx context.Context, models []Model) (missing []Model, err error)
+	FindByKeys(ctx context.Context, model Model, keys ...spanner.Key) ([]Model, error)
+	FindByKeyRange(ctx context.Context, model Model, keyRange spanner.KeyRange) ([]Model, error)
+	ReadUsingIndex(ctx context.Context, model Model, index string, keys spanner.KeySet) ([]Model, error)
 }
 
 type ReadWriter interface {
-	Relation(Model) *QueryContext
+	Reader
 
 	Insert(context.Context, Model) error
 	Update(context.Context, Model) error
+	UpdateColumns(ctx context.Context, model Model, cols ...string) error
 	Delete(context.Context, Model) error
 }
 
 type Mutator interface {
 	Insert(Model)
 	Update(Model)
+	UpdateColumns(model Model, cols ...string)
 	Delete(Model)
 	InsertOrUpdate(Model)
 
 	Do(context.Context, func(context.Context, Mutator) error) error
 	Apply(context.Context) error
+	ApplyWithResult(context.Context, CommitOptions) (CommitResult, error)
 }
 
 type Relation interface {
 	All(ctx context.Context) (rows []Model, err error)
 	Query(ctx context.Context, query string, params map[string]interface{}) (rows []Model, err error)
 	FindOne(ctx context.Context) (row Model, err error)
+	Scan(ctx context.Context, dest interface{}) error
+	QueryInto(ctx context.Context, query string, params map[string]interface{}, dest interface{}) error
+	Count(ctx context.Context) (int64, error)
+	Exists(ctx context.Context) (bool, error)
+	Aggregate(aggs ...Aggregate) *Aggregation
+	Each(ctx context.Context, fn func(Model) error) error
+	Iter(ctx context.Context) *ModelIterator
+	Paginate(ctx context.Context, cursor string, pageSize int) (rows []Model, next string, err error)
 
 	ForceIndex(index string) Relation
+	Unscoped() Relation
+	Scopes(scopes ...Scope) Relation
+	Preload(names ...string) Relation
 	Select(selects []string) Relation
 	Where(param WhereParam) Relation
+	Filter(conds ...Condition) Relation
 	Order(param OrderParam) Relation
+	OrderBy(keys ...OrderKey) Relation
 	Limit(limit int) Relation
+	Offset(offset int) Relation
 
 	SQL() string
 	Table() string
@@ -54,23 +75,40 @@ func New(client *spanner.Client) *DB {
 }
 
 func (db *DB) ReadOnlyTransaction(fn func(Reader)) {
-	fn(NewReadTx(db.client.ReadOnlyTransaction()))
+	tx := db.client.ReadOnlyTransaction()
+	defer tx.Close()
+
+	fn(NewReadTx(tx))
 }
 
 func (db *DB) ReadWriteTransaction(ctx context.Context, fn func(context.Context, ReadWriter) error) error {
-	_, err := db.client.ReadWriteTransaction(ctx, func(ctx context.Context, tx *spanner.ReadWriteTransaction) error {
-		return fn(ctx, NewReadWriteTx(tx))
-	})
-
+	_, err := db.ReadWriteTransactionWithResult(ctx, CommitOptions{}, fn)
 	return err
 }
 
-func (db *DB) Relation(model Model) Relation {
-	return NewQueryContext(model, db.client.Single())
+// ReadWriteTransactionWithResult is ReadWriteTransaction returning the commit
+// timestamp and, when requested, the commit stats.
+func (db *DB) ReadWriteTransactionWithResult(ctx context.Context, opts CommitOptions, fn func(context.Context, ReadWriter) error) (CommitResult, error) {
+	var rw *ReadWriteTx
+	resp, err := db.client.ReadWriteTransactionWithOptions(ctx, func(ctx context.Context, tx *spanner.ReadWriteTransaction) error {
+		rw = NewReadWriteTx(tx)
+		return fn(ctx, rw)
+	}, opts.transactionOptions())
+	if err != nil {
+		return CommitResult{}, err
+	}
+
+	return newCommitResult(resp), rw.effects.apply(resp.CommitTs)
+}
+
+// Relation returns a relation running each query in a new single-use
+// transaction, since one is closed after its first read.
+func (db *DB) Relation(model Model) *QueryContext {
+	return newQueryContext(model, db.single)
 }
 
 func (db *DB) Reader() Reader {
-	return NewReadTx(db.client.Single())
+	return &ReadTx{txFn: db.single, snapshot: db.readOnly}
 }
 
 func (db *DB) Mutator() Mutator {
@@ -81,6 +119,31 @@ func (db *DB) Find(ctx context.Context, model Model) error {
 	return db.Reader().Find(ctx, model)
 }
 
+func (db *DB) FindAll(ctx context.Context, models []Model) ([]Model, error) {
+	return db.Reader().FindAll(ctx, models)
+}
+
+func (db *DB) FindByKeys(ctx context.Context, model Model, keys ...spanner.Key) ([]Model, error) {
+	return db.Reader().FindByKeys(ctx, model, keys...)
+}
+
+func (db *DB) FindByKeyRange(ctx context.Context, model Model, keyRange spanner.KeyRange) ([]Model, error) {
+	return db.Reader().FindByKeyRange(ctx, model, keyRange)
+}
+
+func (db *DB) ReadUsingIndex(ctx context.Context, model Model, index string, keys spanner.KeySet) ([]Model, error) {
+	return db.Reader().ReadUsingIndex(ctx, model, index, keys)
+}
+
+func (db *DB) single() SpannerReader {
+	return db.client.Single()
+}
+
+func (db *DB) readOnly() (SpannerReader, func()) {
+	tx := db.client.ReadOnlyTransaction()
+	return tx, tx.Close
+}
+
 func (db *DB) Close() {
 	db.client.Close()
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/yuemori/blackvice/testdata"
 
 	"cloud.google.com/go/spanner"
+	"google.golang.org/api/iterator"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
@@ -21,10 +23,11 @@ import (
 )
 
 var (
-	dsn      string
-	project  string
-	instance string
-	database string
+	emulatorHost string
+	dsn          string
+	project      string
+	instance     string
+	database     string
 )
 
 func init() {
@@ -34,11 +37,7 @@ func init() {
 		}
 		return defaultValue
 	}
-	emulator_host := env("SPANNER_EMULATOR_HOST", "")
-	if emulator_host == "" {
-		panic("cannot setup spanner because env 'SPANNER_EMULATOR_HOST' is not set")
-	}
-
+	emulatorHost = env("SPANNER_EMULATOR_HOST", "")
 	project = env("SPANNER_GCP_PROJECT", "sql-driver-spanner-project")
 	instance = env("SPANNER_INSTANCE", "sql-driver-spanner-instance")
 	database = env("SPANNER_DATABASE", "testdb")
@@ -46,6 +45,10 @@ func init() {
 }
 
 func runTests(t *testing.T, database string, tests ...func(dbt *blackvice.DB)) {
+	if emulatorHost == "" {
+		t.Skip("cannot setup spanner because env 'SPANNER_EMULATOR_HOST' is not set")
+	}
+
 	ctx := context.Background()
 	defer deleteInstance(ctx, t)
 
@@ -441,16 +444,105 @@ func TestQuery(t *testing.T) {
 			t.Fatalf("User must be 1: %d", len(rows))
 		}
 
-		// TODO: implement
-		// rows, err = db.Relation(&testdata.User{}).Where(map[string]interface{}{
-		// 	"Age": []int{20, 18},
-		// }).All(ctx)
-		// if err != nil {
-		// 	t.Fatalf("Read User failed: %v", err)
-		// }
-		// if len(rows) != 2 {
-		// 	t.Fatalf("User must be 2: %d", len(rows))
-		// }
+		rows, err = db.Relation(&testdata.User{}).Where(map[string]interface{}{
+			"Age": []int64{20, 18},
+		}).All(ctx)
+		if err != nil {
+			t.Fatalf("Read User failed: %v", err)
+		}
+		if len(rows) != 3 {
+			t.Fatalf("User must be 3: %d", len(rows))
+		}
+
+		base := db.Relation(&testdata.User{}).Where(map[string]interface{}{
+			"Age": 20,
+		})
+		baseSQL := base.SQL()
+		narrowed := base.Where(map[string]interface{}{
+			"Name": "test2",
+		}).Limit(1)
+		if base.SQL() != baseSQL {
+			t.Fatalf("Base relation must not be changed: %s", base.SQL())
+		}
+		rows, err = base.All(ctx)
+		if err != nil {
+			t.Fatalf("Read User failed: %v", err)
+		}
+		if len(rows) != 2 {
+			t.Fatalf("User must be 2: %d", len(rows))
+		}
+		rows, err = narrowed.All(ctx)
+		if err != nil {
+			t.Fatalf("Read User failed: %v", err)
+		}
+		if len(rows) != 1 {
+			t.Fatalf("User must be 1: %d", len(rows))
+		}
+
+		count, err := db.Relation(&testdata.User{}).Where(map[string]interface{}{
+			"Age": 20,
+		}).Limit(1).Count(ctx)
+		if err != nil {
+			t.Fatalf("Count User failed: %v", err)
+		}
+		if count != 2 {
+			t.Fatalf("User count must be 2: %d", count)
+		}
+
+		exists, err := db.Relation(&testdata.User{}).Where(map[string]interface{}{
+			"Age": 30,
+		}).Exists(ctx)
+		if err != nil {
+			t.Fatalf("Exists User failed: %v", err)
+		}
+		if exists {
+			t.Fatal("User must not exist")
+		}
+
+		rows, err = db.Relation(&testdata.User{}).OrderBy(blackvice.Asc("UserId")).Offset(1).All(ctx)
+		if err != nil {
+			t.Fatalf("Read User failed: %v", err)
+		}
+		if len(rows) != 2 || rows[0].(*testdata.User).UserId != "userId2" {
+			t.Fatalf("Offset was wrong: %d", len(rows))
+		}
+
+		rows, err = db.Relation(&testdata.User{}).Filter(
+			blackvice.Gte("Age", 20),
+			blackvice.Ne("Name", "test2"),
+		).All(ctx)
+		if err != nil {
+			t.Fatalf("Read User failed: %v", err)
+		}
+		if len(rows) != 1 {
+			t.Fatalf("User must be 1: %d", len(rows))
+		}
+
+		rows, err = db.Relation(&testdata.User{}).Where(map[string]interface{}{
+			"Age": 20,
+		}).Filter(blackvice.Or(
+			blackvice.Eq("Age", 18),
+			blackvice.StartsWith("Name", "test3"),
+		)).All(ctx)
+		if err != nil {
+			t.Fatalf("Read User failed: %v", err)
+		}
+		if len(rows) != 1 {
+			t.Fatalf("User must be 1: %d", len(rows))
+		}
+
+		rows, err = db.Relation(&testdata.User{}).Filter(
+			blackvice.Not(blackvice.In("UserId", []string{"userId1", "userId2"})),
+			blackvice.Between("Age", 19, 21),
+			blackvice.Like("Name", "test%"),
+			blackvice.IsNotNull("Name"),
+		).All(ctx)
+		if err != nil {
+			t.Fatalf("Read User failed: %v", err)
+		}
+		if len(rows) != 1 {
+			t.Fatalf("User must be 1: %d", len(rows))
+		}
 
 		rows, err = db.Relation(&testdata.User{}).Order(map[string]blackvice.Direction{
 			"Age": blackvice.ASC,
@@ -498,5 +590,1063 @@ func TestQuery(t *testing.T) {
 		if rows[0].(*testdata.User).Name != "test3" {
 			t.Fatal("User order was wrong")
 		}
+
+		rows, err = db.Relation(&testdata.User{}).OrderBy(
+			blackvice.Desc("Age"),
+			blackvice.Asc("Name").WithNulls(blackvice.NullsLast),
+		).All(ctx)
+		if err != nil {
+			t.Fatalf("Read User failed: %v", err)
+		}
+		if len(rows) != 3 {
+			t.Fatalf("User must be 3: %d", len(rows))
+		}
+		if rows[0].(*testdata.User).Name != "test2" || rows[2].(*testdata.User).Name != "test1" {
+			t.Fatal("User order was wrong")
+		}
+
+		_, err = db.Relation(&testdata.User{}).Order(map[string]blackvice.Direction{
+			"Age": "ASC; DROP TABLE users",
+		}).All(ctx)
+		if spanner.ErrCode(err) != codes.InvalidArgument {
+			t.Fatalf("Invalid direction must be rejected: %v", err)
+		}
+	})
+}
+
+func TestTypedQuery(t *testing.T) {
+	runTests(t, dsn, func(db *blackvice.DB) {
+		ctx := context.Background()
+
+		err := db.Mutator().Do(ctx, func(ctx context.Context, m blackvice.Mutator) error {
+			m.Insert(&testdata.User{UserId: "userId1", Name: "test1", Age: 18})
+			m.Insert(&testdata.User{UserId: "userId2", Name: "test2", Age: 20})
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Insert Users failed: %v", err)
+		}
+
+		users, err := blackvice.Query[testdata.User](db).OrderBy(blackvice.Asc("Age")).All(ctx)
+		if err != nil {
+			t.Fatalf("Read User failed: %v", err)
+		}
+		if len(users) != 2 {
+			t.Fatalf("User must be 2: %d", len(users))
+		}
+		if users[0].Name != "test1" {
+			t.Fatalf("Expected Name is test1, but %s", users[0].Name)
+		}
+
+		user, err := blackvice.Query[testdata.User](db).Where(map[string]interface{}{
+			"UserId": "userId2",
+		}).FindOne(ctx)
+		if err != nil {
+			t.Fatalf("Read User failed: %v", err)
+		}
+		if user.Age != 20 {
+			t.Fatalf("Expected Age is 20, but %d", user.Age)
+		}
+
+		var names []string
+		err = blackvice.Query[testdata.User](db).OrderBy(blackvice.Desc("Age")).Each(ctx, func(user *testdata.User) error {
+			names = append(names, user.Name)
+			return blackvice.ErrBreak
+		})
+		if err != nil {
+			t.Fatalf("Read User failed: %v", err)
+		}
+		if len(names) != 1 || names[0] != "test2" {
+			t.Fatalf("Each must stop after the first row: %v", names)
+		}
+
+		iter := db.Relation(&testdata.User{}).OrderBy(blackvice.Asc("Age")).Iter(ctx)
+		defer iter.Stop()
+		count := 0
+		for {
+			_, err := iter.Next()
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Read User failed: %v", err)
+			}
+			count++
+		}
+		if count != 2 {
+			t.Fatalf("User must be 2: %d", count)
+		}
+
+		_, err = blackvice.Query[testdata.User](db.Reader()).Where(map[string]interface{}{
+			"UserId": "unknown",
+		}).FindOne(ctx)
+		if !blackvice.IsErrNotFound(err) {
+			t.Fatalf("User must not be found: %v", err)
+		}
+	})
+}
+
+func TestPaginate(t *testing.T) {
+	runTests(t, dsn, func(db *blackvice.DB) {
+		ctx := context.Background()
+
+		err := db.Mutator().Do(ctx, func(ctx context.Context, m blackvice.Mutator) error {
+			for i := 1; i <= 5; i++ {
+				m.Insert(&testdata.User{
+					UserId: fmt.Sprintf("userId%d", i),
+					Name:   fmt.Sprintf("test%d", i),
+					Age:    int64(18 + i%2),
+				})
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Insert Users failed: %v", err)
+		}
+
+		var ids []string
+		cursor := ""
+		pages := 0
+		for {
+			rows, next, err := db.Relation(&testdata.User{}).OrderBy(blackvice.Desc("Age")).Paginate(ctx, cursor, 2)
+			if err != nil {
+				t.Fatalf("Paginate User failed: %v", err)
+			}
+			for _, row := range rows {
+				ids = append(ids, row.(*testdata.User).UserId)
+			}
+			pages++
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+
+		expected := []string{"userId1", "userId3", "userId5", "userId2", "userId4"}
+		if fmt.Sprint(ids) != fmt.Sprint(expected) {
+			t.Fatalf("Expected %v, but %v", expected, ids)
+		}
+		if pages != 3 {
+			t.Fatalf("Pages must be 3: %d", pages)
+		}
+
+		users, next, err := blackvice.Query[testdata.User](db).Paginate(ctx, "", 4)
+		if err != nil {
+			t.Fatalf("Paginate User failed: %v", err)
+		}
+		if len(users) != 4 || next == "" {
+			t.Fatalf("Unexpected first page: %d, %q", len(users), next)
+		}
+
+		_, _, err = db.Relation(&testdata.User{}).OrderBy(blackvice.Asc("Name")).Paginate(ctx, next, 4)
+		if spanner.ErrCode(err) != codes.InvalidArgument {
+			t.Fatalf("Cursor of another order must be rejected: %v", err)
+		}
+
+		err = db.Mutator().Do(ctx, func(ctx context.Context, m blackvice.Mutator) error {
+			for i := 1; i <= 4; i++ {
+				post := &testdata.Post{UserId: "userId1", PostId: int64(i), Title: fmt.Sprintf("title%d", i)}
+				if i%2 == 0 {
+					post.Body = spanner.NullString{StringVal: fmt.Sprintf("body%d", i), Valid: true}
+				}
+				m.Insert(post)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Insert Posts failed: %v", err)
+		}
+
+		for _, key := range []blackvice.OrderKey{
+			blackvice.Asc("Body"),
+			blackvice.Desc("Body"),
+			blackvice.Asc("Body").WithNulls(blackvice.NullsLast),
+			blackvice.Desc("Body").WithNulls(blackvice.NullsFirst),
+		} {
+			var postIds []int64
+			cursor := ""
+			for {
+				posts, next, err := blackvice.Query[testdata.Post](db).OrderBy(key).Paginate(ctx, cursor, 1)
+				if err != nil {
+					t.Fatalf("Paginate Post failed: %v", err)
+				}
+				for _, post := range posts {
+					postIds = append(postIds, post.PostId)
+				}
+				if next == "" {
+					break
+				}
+				cursor = next
+			}
+			if len(postIds) != 4 {
+				t.Fatalf("Pages ordered by %v must contain every Post once: %v", key, postIds)
+			}
+		}
+	})
+}
+
+func TestAggregate(t *testing.T) {
+	runTests(t, dsn, func(db *blackvice.DB) {
+		ctx := context.Background()
+
+		err := db.Mutator().Do(ctx, func(ctx context.Context, m blackvice.Mutator) error {
+			m.Insert(&testdata.User{UserId: "userId1", Name: "test1", Age: 18})
+			m.Insert(&testdata.User{UserId: "userId2", Name: "test2", Age: 20})
+			m.Insert(&testdata.User{UserId: "userId3", Name: "test3", Age: 20})
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Insert Users failed: %v", err)
+		}
+
+		var sum int64
+		err = db.Relation(&testdata.User{}).Aggregate(blackvice.Sum("Age")).Scalar(ctx, &sum)
+		if err != nil {
+			t.Fatalf("Aggregate User failed: %v", err)
+		}
+		if sum != 58 {
+			t.Fatalf("Sum of Age must be 58: %d", sum)
+		}
+
+		rows, err := db.Relation(&testdata.User{}).
+			OrderBy(blackvice.Asc("Age")).
+			Aggregate(blackvice.CountAll().As("total"), blackvice.Max("Name")).
+			GroupBy("Age").
+			Having(blackvice.Gt("total", 1)).
+			Rows(ctx)
+		if err != nil {
+			t.Fatalf("Aggregate User failed: %v", err)
+		}
+		if len(rows) != 1 {
+			t.Fatalf("Groups must be 1: %d", len(rows))
+		}
+		if rows[0]["Age"] != int64(20) || rows[0]["total"] != int64(2) || rows[0]["max_Name"] != "test3" {
+			t.Fatalf("Unexpected group: %v", rows[0])
+		}
+	})
+}
+
+func TestScan(t *testing.T) {
+	runTests(t, dsn, func(db *blackvice.DB) {
+		ctx := context.Background()
+
+		err := db.Mutator().Do(ctx, func(ctx context.Context, m blackvice.Mutator) error {
+			m.Insert(&testdata.User{UserId: "userId1", Name: "test1", Age: 18})
+			m.Insert(&testdata.User{UserId: "userId2", Name: "test2", Age: 20})
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Insert Users failed: %v", err)
+		}
+
+		var names []string
+		err = db.Relation(&testdata.User{}).Select([]string{"Name"}).OrderBy(blackvice.Asc("Name")).Scan(ctx, &names)
+		if err != nil {
+			t.Fatalf("Scan User failed: %v", err)
+		}
+		if fmt.Sprint(names) != "[test1 test2]" {
+			t.Fatalf("Unexpected names: %v", names)
+		}
+
+		type summary struct {
+			UserId string
+			Older  bool
+		}
+		var summaries []*summary
+		err = db.Relation(&testdata.User{}).QueryInto(ctx, "SELECT UserId, Age > @age AS Older FROM users ORDER BY UserId", map[string]interface{}{
+			"age": 19,
+		}, &summaries)
+		if err != nil {
+			t.Fatalf("Scan User failed: %v", err)
+		}
+		if len(summaries) != 2 || summaries[0].Older || !summaries[1].Older {
+			t.Fatalf("Unexpected summaries: %v", summaries)
+		}
+
+		var groups []struct {
+			Age   int64
+			Total int64
+		}
+		err = db.Relation(&testdata.User{}).Aggregate(blackvice.CountAll().As("Total")).GroupBy("Age").Scan(ctx, &groups)
+		if err != nil {
+			t.Fatalf("Scan User failed: %v", err)
+		}
+		if len(groups) != 2 {
+			t.Fatalf("Groups must be 2: %d", len(groups))
+		}
+	})
+}
+
+func TestReadAPI(t *testing.T) {
+	runTests(t, dsn, func(db *blackvice.DB) {
+		ctx := context.Background()
+
+		err := db.Mutator().Do(ctx, func(ctx context.Context, m blackvice.Mutator) error {
+			m.Insert(&testdata.User{UserId: "userId1", Name: "test1", Age: 18})
+			m.Insert(&testdata.User{UserId: "userId2", Name: "test2", Age: 20})
+			m.Insert(&testdata.User{UserId: "userId3", Name: "test3", Age: 20})
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Insert Users failed: %v", err)
+		}
+
+		rows, err := db.FindByKeys(ctx, &testdata.User{}, spanner.Key{"userId1"}, spanner.Key{"userId3"}, spanner.Key{"unknown"})
+		if err != nil {
+			t.Fatalf("Read User failed: %v", err)
+		}
+		if len(rows) != 2 {
+			t.Fatalf("User must be 2: %d", len(rows))
+		}
+
+		rows, err = db.FindByKeyRange(ctx, &testdata.User{}, spanner.KeyRange{
+			Start: spanner.Key{"userId2"},
+			End:   spanner.Key{"userId3"},
+			Kind:  spanner.ClosedClosed,
+		})
+		if err != nil {
+			t.Fatalf("Read User failed: %v", err)
+		}
+		if len(rows) != 2 {
+			t.Fatalf("User must be 2: %d", len(rows))
+		}
+
+		users := []blackvice.Model{
+			&testdata.User{UserId: "userId3"},
+			&testdata.User{UserId: "unknown"},
+			&testdata.User{UserId: "userId1"},
+		}
+		missing, err := db.FindAll(ctx, users)
+		if err != nil {
+			t.Fatalf("Read User failed: %v", err)
+		}
+		if len(missing) != 1 || missing[0].(*testdata.User).UserId != "unknown" {
+			t.Fatalf("Unexpected missing Users: %v", missing)
+		}
+		if users[0].(*testdata.User).Name != "test3" || users[2].(*testdata.User).Name != "test1" {
+			t.Fatal("Users must be filled in place")
+		}
+
+		rows, err = db.ReadUsingIndex(ctx, &testdata.User{}, "UsersByName", spanner.Key{"test2"})
+		if err != nil {
+			t.Fatalf("Read User failed: %v", err)
+		}
+		if len(rows) != 1 {
+			t.Fatalf("User must be 1: %d", len(rows))
+		}
+		if user := rows[0].(*testdata.User); user.UserId != "userId2" || user.Age != 20 {
+			t.Fatalf("Unexpected User: %v", user)
+		}
+	})
+}
+
+func TestAutoModel(t *testing.T) {
+	runTests(t, dsn, func(db *blackvice.DB) {
+		ctx := context.Background()
+
+		if err := blackvice.Register(&testdata.Post{}); err != nil {
+			t.Fatalf("Register Post failed: %v", err)
+		}
+
+		err := db.ReadWriteTransaction(ctx, func(ctx context.Context, tx blackvice.ReadWriter) error {
+			if err := tx.Insert(ctx, &testdata.User{UserId: "userId1", Name: "test1"}); err != nil {
+				return err
+			}
+			if err := tx.Insert(ctx, &testdata.Post{UserId: "userId1", PostId: 1, Title: "first"}); err != nil {
+				return err
+			}
+			return tx.Insert(ctx, &testdata.Post{UserId: "userId1", PostId: 2, Title: "second"})
+		})
+		if err != nil {
+			t.Fatalf("Insert Posts failed: %v", err)
+		}
+
+		post := &testdata.Post{UserId: "userId1", PostId: 2}
+		if err := db.Find(ctx, post); err != nil {
+			t.Fatalf("Read Post failed: %v", err)
+		}
+		if post.Title != "second" {
+			t.Fatalf("Expected Title is second, but %s", post.Title)
+		}
+
+		posts, err := blackvice.Query[testdata.Post](db).OrderBy(blackvice.Desc("PostId")).All(ctx)
+		if err != nil {
+			t.Fatalf("Read Post failed: %v", err)
+		}
+		if len(posts) != 2 || posts[0].PostId != 2 {
+			t.Fatalf("Unexpected Posts: %v", posts)
+		}
+		if key := posts[1].SpannerKey(); key.String() != `("userId1",1)` {
+			t.Fatalf("Unexpected key: %v", key)
+		}
+	})
+}
+
+func TestPartialUpdate(t *testing.T) {
+	runTests(t, dsn, func(db *blackvice.DB) {
+		ctx := context.Background()
+
+		err := db.Mutator().Do(ctx, func(ctx context.Context, m blackvice.Mutator) error {
+			m.Insert(&testdata.User{UserId: "userId1", Name: "test1"})
+			m.Insert(&testdata.Post{UserId: "userId1", PostId: 1, Title: "title"})
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Insert Post failed: %v", err)
+		}
+
+		first := &testdata.Post{UserId: "userId1", PostId: 1}
+		second := &testdata.Post{UserId: "userId1", PostId: 1}
+		if err := db.Find(ctx, first); err != nil {
+			t.Fatalf("Read Post failed: %v", err)
+		}
+		if err := db.Find(ctx, second); err != nil {
+			t.Fatalf("Read Post failed: %v", err)
+		}
+
+		first.Title = "updated"
+		err = db.ReadWriteTransaction(ctx, func(ctx context.Context, tx blackvice.ReadWriter) error {
+			return tx.Update(ctx, first)
+		})
+		if err != nil {
+			t.Fatalf("Update Post failed: %v", err)
+		}
+
+		second.Body = spanner.NullString{StringVal: "body", Valid: true}
+		m := db.Mutator()
+		m.Update(second)
+		if err := m.Apply(ctx); spanner.ErrCode(err) != codes.FailedPrecondition {
+			t.Fatalf("Mutations of Versioned models must be refused: %v", err)
+		}
+
+		if err := db.Find(ctx, second); err != nil {
+			t.Fatalf("Read Post failed: %v", err)
+		}
+		second.Body = spanner.NullString{StringVal: "body", Valid: true}
+		err = db.ReadWriteTransaction(ctx, func(ctx context.Context, tx blackvice.ReadWriter) error {
+			return tx.Update(ctx, second)
+		})
+		if err != nil {
+			t.Fatalf("Update Post failed: %v", err)
+		}
+
+		res := &testdata.Post{UserId: "userId1", PostId: 1}
+		if err := db.Find(ctx, res); err != nil {
+			t.Fatalf("Read Post failed: %v", err)
+		}
+		if res.Title != "updated" || res.Body.StringVal != "body" {
+			t.Fatalf("Concurrent updates must not clobber each other: %v", res.Params())
+		}
+
+		res.Title = "explicit"
+		res.Body = spanner.NullString{}
+		err = db.ReadWriteTransaction(ctx, func(ctx context.Context, tx blackvice.ReadWriter) error {
+			return tx.UpdateColumns(ctx, res, "Title")
+		})
+		if err != nil {
+			t.Fatalf("Update Post failed: %v", err)
+		}
+
+		res2 := &testdata.Post{UserId: "userId1", PostId: 1}
+		if err := db.Find(ctx, res2); err != nil {
+			t.Fatalf("Read Post failed: %v", err)
+		}
+		if res2.Title != "explicit" || res2.Body.StringVal != "body" {
+			t.Fatalf("UpdateColumns must write only the given columns: %v", res2.Params())
+		}
+
+		res2.Title = "rolled back"
+		err = db.ReadWriteTransaction(ctx, func(ctx context.Context, tx blackvice.ReadWriter) error {
+			if err := tx.Update(ctx, res2); err != nil {
+				return err
+			}
+			return fmt.Errorf("rollback")
+		})
+		if err == nil {
+			t.Fatalf("Transaction must fail")
+		}
+		if res2.Version != 3 {
+			t.Fatalf("A rolled back update must not bump the version: %d", res2.Version)
+		}
+		err = db.ReadWriteTransaction(ctx, func(ctx context.Context, tx blackvice.ReadWriter) error {
+			return tx.Update(ctx, res2)
+		})
+		if err != nil {
+			t.Fatalf("Update Post failed: %v", err)
+		}
+
+		res3 := &testdata.Post{UserId: "userId1", PostId: 1}
+		if err := db.Find(ctx, res3); err != nil {
+			t.Fatalf("Read Post failed: %v", err)
+		}
+		if res3.Title != "rolled back" {
+			t.Fatalf("A rolled back update must not mark the columns as written: %v", res3.Params())
+		}
+	})
+}
+
+func TestOptimisticLock(t *testing.T) {
+	runTests(t, dsn, func(db *blackvice.DB) {
+		ctx := context.Background()
+
+		err := db.Mutator().Do(ctx, func(ctx context.Context, m blackvice.Mutator) error {
+			m.Insert(&testdata.User{UserId: "userId1", Name: "test1"})
+			m.Insert(&testdata.Post{UserId: "userId1", PostId: 1, Title: "title"})
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Insert Post failed: %v", err)
+		}
+
+		first := &testdata.Post{UserId: "userId1", PostId: 1}
+		second := &testdata.Post{UserId: "userId1", PostId: 1}
+		if err := db.Find(ctx, first); err != nil {
+			t.Fatalf("Read Post failed: %v", err)
+		}
+		if err := db.Find(ctx, second); err != nil {
+			t.Fatalf("Read Post failed: %v", err)
+		}
+
+		first.Title = "first"
+		err = db.ReadWriteTransaction(ctx, func(ctx context.Context, tx blackvice.ReadWriter) error {
+			return tx.Update(ctx, first)
+		})
+		if err != nil {
+			t.Fatalf("Update Post failed: %v", err)
+		}
+		if first.Version != 1 {
+			t.Fatalf("Expected Version is 1, but %d", first.Version)
+		}
+
+		second.Title = "second"
+		err = db.ReadWriteTransaction(ctx, func(ctx context.Context, tx blackvice.ReadWriter) error {
+			return tx.Update(ctx, second)
+		})
+		if !blackvice.IsErrStaleObject(err) {
+			t.Fatalf("Expected ErrStaleObject, but %v", err)
+		}
+
+		err = db.ReadWriteTransaction(ctx, func(ctx context.Context, tx blackvice.ReadWriter) error {
+			return tx.Delete(ctx, second)
+		})
+		if !blackvice.IsErrStaleObject(err) {
+			t.Fatalf("Expected ErrStaleObject, but %v", err)
+		}
+
+		res := &testdata.Post{UserId: "userId1", PostId: 1}
+		if err := db.Find(ctx, res); err != nil {
+			t.Fatalf("Read Post failed: %v", err)
+		}
+		if res.Title != "first" || res.Version != 1 {
+			t.Fatalf("Stale update must not be written: %v", res.Params())
+		}
+
+		err = db.ReadWriteTransaction(ctx, func(ctx context.Context, tx blackvice.ReadWriter) error {
+			first.Title = "twice"
+			if err := tx.Update(ctx, first); err != nil {
+				return err
+			}
+			first.Body = spanner.NullString{StringVal: "body", Valid: true}
+			return tx.Update(ctx, first)
+		})
+		if err != nil {
+			t.Fatalf("Update Post failed: %v", err)
+		}
+		if first.Version != 3 {
+			t.Fatalf("Expected Version is 3, but %d", first.Version)
+		}
+
+		err = db.ReadWriteTransaction(ctx, func(ctx context.Context, tx blackvice.ReadWriter) error {
+			return tx.Delete(ctx, first)
+		})
+		if err != nil {
+			t.Fatalf("Delete Post failed: %v", err)
+		}
+	})
+}
+
+func TestCommitTimestamp(t *testing.T) {
+	runTests(t, dsn, func(db *blackvice.DB) {
+		ctx := context.Background()
+
+		user := &testdata.User{UserId: "userId1", Name: "test1"}
+		m := db.Mutator()
+		m.Insert(user)
+		if err := m.Apply(ctx); err != nil {
+			t.Fatalf("Insert User failed: %v", err)
+		}
+		if user.CreatedAt.IsZero() || !user.CreatedAt.Equal(user.UpdatedAt) {
+			t.Fatalf("Expected commit timestamps, but %v", user.Params())
+		}
+
+		res := &testdata.User{UserId: "userId1"}
+		if err := db.Find(ctx, res); err != nil {
+			t.Fatalf("Read User failed: %v", err)
+		}
+		if !res.CreatedAt.Equal(user.CreatedAt) {
+			t.Fatalf("Expected CreatedAt is %v, but %v", user.CreatedAt, res.CreatedAt)
+		}
+
+		res.Name = "updated"
+		err := db.ReadWriteTransaction(ctx, func(ctx context.Context, tx blackvice.ReadWriter) error {
+			return tx.Update(ctx, res)
+		})
+		if err != nil {
+			t.Fatalf("Update User failed: %v", err)
+		}
+		if !res.UpdatedAt.After(user.UpdatedAt) {
+			t.Fatalf("Expected UpdatedAt after %v, but %v", user.UpdatedAt, res.UpdatedAt)
+		}
+
+		res2 := &testdata.User{UserId: "userId1"}
+		if err := db.Find(ctx, res2); err != nil {
+			t.Fatalf("Read User failed: %v", err)
+		}
+		if !res2.CreatedAt.Equal(user.CreatedAt) || !res2.UpdatedAt.Equal(res.UpdatedAt) {
+			t.Fatalf("Unexpected timestamps: %v", res2.Params())
+		}
+	})
+}
+
+func TestCommitResult(t *testing.T) {
+	runTests(t, dsn, func(db *blackvice.DB) {
+		ctx := context.Background()
+
+		user := &testdata.User{UserId: "userId1", Name: "test1"}
+		m := db.Mutator()
+		m.Insert(user)
+		res, err := m.ApplyWithResult(ctx, blackvice.CommitOptions{ReturnCommitStats: true})
+		if err != nil {
+			t.Fatalf("Insert User failed: %v", err)
+		}
+		if !res.Timestamp.Equal(user.CreatedAt) {
+			t.Fatalf("Expected commit timestamp is %v, but %v", user.CreatedAt, res.Timestamp)
+		}
+		// the 5 columns of the row and its entry in UsersByName
+		if res.MutationCount != 6 {
+			t.Fatalf("Expected MutationCount is 6, but %d", res.MutationCount)
+		}
+
+		user.Name = "updated"
+		res2, err := db.ReadWriteTransactionWithResult(ctx, blackvice.CommitOptions{}, func(ctx context.Context, tx blackvice.ReadWriter) error {
+			return tx.Update(ctx, user)
+		})
+		if err != nil {
+			t.Fatalf("Update User failed: %v", err)
+		}
+		if !res2.Timestamp.After(res.Timestamp) || !res2.Timestamp.Equal(user.UpdatedAt) {
+			t.Fatalf("Unexpected commit timestamp: %v", res2.Timestamp)
+		}
+		if res2.MutationCount != 0 {
+			t.Fatalf("MutationCount must be 0 unless requested, but %d", res2.MutationCount)
+		}
+	})
+}
+
+func TestHooks(t *testing.T) {
+	runTests(t, dsn, func(db *blackvice.DB) {
+		ctx := context.Background()
+
+		m := db.Mutator()
+		m.Insert(&testdata.AuditedUser{User: testdata.User{UserId: "userId2", Name: " "}})
+		m.Insert(&testdata.AuditedUser{User: testdata.User{UserId: "userId3", Name: "test3"}})
+		if err := m.Apply(ctx); err != testdata.ErrEmptyName {
+			t.Fatalf("Expected ErrEmptyName, but %v", err)
+		}
+
+		user := &testdata.AuditedUser{User: testdata.User{UserId: "userId1", Name: "  test1  "}}
+		m.Insert(user)
+		// the write is built when added
+		user.Name = "changed"
+		if err := m.Apply(ctx); err != nil {
+			t.Fatalf("Insert User failed: %v", err)
+		}
+
+		cnt, err := db.Relation(&testdata.User{}).Count(ctx)
+		if err != nil {
+			t.Fatalf("Count Users failed: %v", err)
+		}
+		if cnt != 1 {
+			t.Fatalf("Writes of a failed Apply must be discarded: %d", cnt)
+		}
+
+		res := &testdata.AuditedUser{User: testdata.User{UserId: "userId1"}}
+		if err := db.Find(ctx, res); err != nil {
+			t.Fatalf("Read User failed: %v", err)
+		}
+		if res.Name != "test1" {
+			t.Fatalf("BeforeInsert must normalize Name, but %q", res.Name)
+		}
+
+		res.Name = " "
+		err = db.ReadWriteTransaction(ctx, func(ctx context.Context, tx blackvice.ReadWriter) error {
+			return tx.Update(ctx, res)
+		})
+		if err != testdata.ErrEmptyName {
+			t.Fatalf("Expected ErrEmptyName, but %v", err)
+		}
+
+		res.Name = "updated "
+		err = db.ReadWriteTransaction(ctx, func(ctx context.Context, tx blackvice.ReadWriter) error {
+			if err := tx.Update(ctx, res); err != nil {
+				return err
+			}
+			return tx.Delete(ctx, res)
+		})
+		if err != nil {
+			t.Fatalf("Update User failed: %v", err)
+		}
+
+		expected := []string{"insert"}
+		if fmt.Sprint(user.Events) != fmt.Sprint(expected) {
+			t.Fatalf("Expected events are %v, but %v", expected, user.Events)
+		}
+		expected = []string{"find", "update", "delete"}
+		if fmt.Sprint(res.Events) != fmt.Sprint(expected) {
+			t.Fatalf("Expected events are %v, but %v", expected, res.Events)
+		}
+	})
+}
+
+func TestValidation(t *testing.T) {
+	runTests(t, dsn, func(db *blackvice.DB) {
+		ctx := context.Background()
+
+		user := &testdata.User{Name: strings.Repeat("a", 40), Age: -1}
+		err := db.ReadWriteTransaction(ctx, func(ctx context.Context, tx blackvice.ReadWriter) error {
+			return tx.Insert(ctx, user)
+		})
+		invalid, ok := err.(*blackvice.ValidationError)
+		if !ok {
+			t.Fatalf("Expected ValidationError, but %v", err)
+		}
+		var columns []string
+		for _, f := range invalid.Fields {
+			columns = append(columns, f.Column+":"+f.Rule)
+		}
+		if expected := "UserId:required Name:max Age:min"; strings.Join(columns, " ") != expected {
+			t.Fatalf("Expected failing fields are %s, but %v", expected, columns)
+		}
+
+		m := db.Mutator()
+		m.Insert(&testdata.User{UserId: "userId1", Name: "test1"})
+		m.Insert(&testdata.Post{UserId: "userId1", PostId: 1})
+		if err := m.Apply(ctx); !blackvice.IsErrValidation(err) {
+			t.Fatalf("Expected ValidationError, but %v", err)
+		}
+
+		cnt, err := db.Relation(&testdata.User{}).Count(ctx)
+		if err != nil {
+			t.Fatalf("Count User failed: %v", err)
+		}
+		if cnt != 0 {
+			t.Fatalf("Invalid writes must not reach Spanner: %d", cnt)
+		}
+	})
+}
+
+func TestSoftDelete(t *testing.T) {
+	runTests(t, dsn, func(db *blackvice.DB) {
+		ctx := context.Background()
+
+		posts := []*testdata.Post{
+			{UserId: "userId1", PostId: 1, Title: "first"},
+			{UserId: "userId1", PostId: 2, Title: "second"},
+			{UserId: "userId1", PostId: 3, Title: "third"},
+		}
+		err := db.Mutator().Do(ctx, func(ctx context.Context, m blackvice.Mutator) error {
+			m.Insert(&testdata.User{UserId: "userId1", Name: "test1"})
+			for _, post := range posts {
+				m.Insert(post)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Insert Posts failed: %v", err)
+		}
+
+		err = db.ReadWriteTransaction(ctx, func(ctx context.Context, tx blackvice.ReadWriter) error {
+			return tx.Delete(ctx, posts[0])
+		})
+		if err != nil {
+			t.Fatalf("Delete Post failed: %v", err)
+		}
+		m := db.Mutator()
+		m.Delete(posts[1])
+		if err := m.Apply(ctx); spanner.ErrCode(err) != codes.FailedPrecondition {
+			t.Fatalf("Mutations of Versioned models must be refused: %v", err)
+		}
+		err = db.ReadWriteTransaction(ctx, func(ctx context.Context, tx blackvice.ReadWriter) error {
+			return tx.Delete(ctx, posts[1])
+		})
+		if err != nil {
+			t.Fatalf("Delete Post failed: %v", err)
+		}
+		if !posts[0].DeletedAt.Valid || !posts[1].DeletedAt.Valid {
+			t.Fatalf("Expected DeletedAt to be set: %v, %v", posts[0].DeletedAt, posts[1].DeletedAt)
+		}
+
+		err = db.Find(ctx, &testdata.Post{UserId: "userId1", PostId: 1})
+		if !blackvice.IsErrNotFound(err) {
+			t.Fatalf("Soft deleted Post must not be found: %v", err)
+		}
+
+		rows, err := db.FindByKeys(ctx, &testdata.Post{}, spanner.Key{"userId1", 1}, spanner.Key{"userId1", 2}, spanner.Key{"userId1", 3})
+		if err != nil {
+			t.Fatalf("Read Posts failed: %v", err)
+		}
+		if len(rows) != 1 {
+			t.Fatalf("Expected 1 Post, but %d", len(rows))
+		}
+
+		cnt, err := db.Relation(&testdata.Post{}).Count(ctx)
+		if err != nil {
+			t.Fatalf("Count Posts failed: %v", err)
+		}
+		if cnt != 1 {
+			t.Fatalf("Expected 1 Post, but %d", cnt)
+		}
+
+		all, err := blackvice.Query[testdata.Post](db).Unscoped().All(ctx)
+		if err != nil {
+			t.Fatalf("Read Posts failed: %v", err)
+		}
+		if len(all) != 3 {
+			t.Fatalf("Expected 3 Posts, but %d", len(all))
+		}
+
+		note := &testdata.Note{Zone: "zone1", Id: 7, Text: "note"}
+		err = db.Mutator().Do(ctx, func(ctx context.Context, m blackvice.Mutator) error {
+			m.Insert(note)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Insert Note failed: %v", err)
+		}
+		m = db.Mutator()
+		m.Delete(note)
+		if err := m.Apply(ctx); err != nil {
+			t.Fatalf("Delete Note failed: %v", err)
+		}
+
+		notes, err := db.Relation(&testdata.Note{}).Unscoped().All(ctx)
+		if err != nil {
+			t.Fatalf("Read Notes failed: %v", err)
+		}
+		if len(notes) != 1 || !notes[0].(*testdata.Note).DeletedAt.Valid {
+			t.Fatalf("Expected the Note to be soft deleted: %v", notes)
+		}
+	})
+}
+
+func adults(r blackvice.Relation) blackvice.Relation {
+	return r.Filter(blackvice.Gte("Age", 20))
+}
+
+func namePrefix(prefix string) blackvice.Scope {
+	return func(r blackvice.Relation) blackvice.Relation {
+		return r.Filter(blackvice.StartsWith("Name", prefix))
+	}
+}
+
+func TestScopes(t *testing.T) {
+	runTests(t, dsn, func(db *blackvice.DB) {
+		ctx := context.Background()
+
+		err := db.Mutator().Do(ctx, func(ctx context.Context, m blackvice.Mutator) error {
+			m.Insert(&testdata.User{UserId: "userId1", Name: "test2", Age: 18})
+			m.Insert(&testdata.User{UserId: "userId2", Name: "test1", Age: 20})
+			m.Insert(&testdata.User{UserId: "userId3", Name: "", Age: 30})
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Insert Users failed: %v", err)
+		}
+
+		rows, err := db.Relation(&testdata.User{}).Scopes(adults, namePrefix("test")).All(ctx)
+		if err != nil {
+			t.Fatalf("Read Users failed: %v", err)
+		}
+		if len(rows) != 1 || rows[0].(*testdata.User).UserId != "userId2" {
+			t.Fatalf("Unexpected Users: %v", rows)
+		}
+
+		users, err := blackvice.Query[testdata.AuditedUser](db).All(ctx)
+		if err != nil {
+			t.Fatalf("Read Users failed: %v", err)
+		}
+		if len(users) != 2 || users[0].Name != "test1" || users[1].Name != "test2" {
+			t.Fatalf("Default scope must filter and order Users: %v", users)
+		}
+
+		users, err = blackvice.Query[testdata.AuditedUser](db).Scopes(adults).All(ctx)
+		if err != nil {
+			t.Fatalf("Read Users failed: %v", err)
+		}
+		if len(users) != 1 || users[0].UserId != "userId2" {
+			t.Fatalf("Unexpected Users: %v", users)
+		}
+
+		cnt, err := blackvice.Query[testdata.AuditedUser](db).Unscoped().Count(ctx)
+		if err != nil {
+			t.Fatalf("Count Users failed: %v", err)
+		}
+		if cnt != 3 {
+			t.Fatalf("Expected 3 Users, but %d", cnt)
+		}
+
+		var ids []string
+		cursor := ""
+		for {
+			page, next, err := blackvice.Query[testdata.AuditedUser](db).Paginate(ctx, cursor, 1)
+			if err != nil {
+				t.Fatalf("Paginate Users failed: %v", err)
+			}
+			for _, user := range page {
+				ids = append(ids, user.UserId)
+			}
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+		if expected := "[userId2 userId1]"; fmt.Sprint(ids) != expected {
+			t.Fatalf("Paginate must follow the default scope order %s, but %v", expected, ids)
+		}
+	})
+}
+
+func TestTenant(t *testing.T) {
+	runTests(t, dsn, func(db *blackvice.DB) {
+		ctx := context.Background()
+
+		err := db.Mutator().Do(ctx, func(ctx context.Context, m blackvice.Mutator) error {
+			m.Insert(&testdata.User{UserId: "userId1", Name: "test1"})
+			m.Insert(&testdata.User{UserId: "userId2", Name: "test2"})
+			m.Insert(&testdata.Post{UserId: "userId1", PostId: 1, Title: "first"})
+			m.Insert(&testdata.Post{UserId: "userId1", PostId: 2, Title: "second"})
+			m.Insert(&testdata.Post{UserId: "userId2", PostId: 1, Title: "other"})
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Insert Posts failed: %v", err)
+		}
+
+		tdb := db.Tenant("UserId", "userId1")
+		denied := func(err error) bool {
+			return spanner.ErrCode(err) == codes.PermissionDenied
+		}
+
+		posts, err := blackvice.Query[testdata.Post](tdb).All(ctx)
+		if err != nil {
+			t.Fatalf("Read Posts failed: %v", err)
+		}
+		if len(posts) != 2 {
+			t.Fatalf("Expected 2 Posts of the tenant, but %d", len(posts))
+		}
+
+		cnt, err := tdb.Relation(&testdata.Post{}).Where(blackvice.WhereParam{"UserId": "userId2"}).Count(ctx)
+		if err != nil {
+			t.Fatalf("Count Posts failed: %v", err)
+		}
+		if cnt != 0 {
+			t.Fatalf("Posts of other tenants must not be read: %d", cnt)
+		}
+
+		if err := tdb.Find(ctx, &testdata.Post{UserId: "userId2", PostId: 1}); !denied(err) {
+			t.Fatalf("Expected PermissionDenied, but %v", err)
+		}
+		if _, err := tdb.FindByKeys(ctx, &testdata.Post{}, spanner.Key{"userId2", 1}); !denied(err) {
+			t.Fatalf("Expected PermissionDenied, but %v", err)
+		}
+
+		_, err = tdb.Relation(&testdata.Post{}).Query(ctx, "SELECT * FROM posts", nil)
+		if !denied(err) {
+			t.Fatalf("Expected PermissionDenied, but %v", err)
+		}
+		rows, err := tdb.Relation(&testdata.Post{}).Query(ctx, "SELECT * FROM posts WHERE UserId = @blackvice_tenant", nil)
+		if err != nil {
+			t.Fatalf("Read Posts failed: %v", err)
+		}
+		if len(rows) != 2 {
+			t.Fatalf("Expected 2 Posts of the tenant, but %d", len(rows))
+		}
+
+		err = tdb.ReadWriteTransaction(ctx, func(ctx context.Context, tx blackvice.ReadWriter) error {
+			return tx.Insert(ctx, &testdata.Post{UserId: "userId2", PostId: 2, Title: "third"})
+		})
+		if !denied(err) {
+			t.Fatalf("Expected PermissionDenied, but %v", err)
+		}
+
+		m := tdb.Mutator()
+		m.Insert(&testdata.Post{UserId: "userId1", PostId: 3, Title: "third"})
+		m.Delete(&testdata.Post{UserId: "userId2", PostId: 1})
+		if err := m.Apply(ctx); !denied(err) {
+			t.Fatalf("Expected PermissionDenied, but %v", err)
+		}
+
+		all, err := db.Relation(&testdata.Post{}).Count(ctx)
+		if err != nil {
+			t.Fatalf("Count Posts failed: %v", err)
+		}
+		if all != 3 {
+			t.Fatalf("Refused writes must not be applied: %d", all)
+		}
+	})
+}
+
+func TestPreload(t *testing.T) {
+	runTests(t, dsn, func(db *blackvice.DB) {
+		ctx := context.Background()
+
+		err := db.Mutator().Do(ctx, func(ctx context.Context, m blackvice.Mutator) error {
+			m.Insert(&testdata.User{UserId: "userId1", Name: "test1"})
+			m.Insert(&testdata.User{UserId: "userId2", Name: "test2"})
+			m.Insert(&testdata.User{UserId: "userId3", Name: "test3"})
+			m.Insert(&testdata.Post{UserId: "userId1", PostId: 1, Title: "first"})
+			m.Insert(&testdata.Post{UserId: "userId1", PostId: 2, Title: "second"})
+			m.Insert(&testdata.Post{UserId: "userId2", PostId: 1, Title: "other"})
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Insert Posts failed: %v", err)
+		}
+
+		rows, err := db.Relation(&testdata.User{}).Preload("Posts").OrderBy(blackvice.Asc("UserId")).All(ctx)
+		if err != nil {
+			t.Fatalf("Read Users failed: %v", err)
+		}
+		if len(rows) != 3 {
+			t.Fatalf("Expected 3 Users, but %d", len(rows))
+		}
+		counts := []int{}
+		for _, row := range rows {
+			counts = append(counts, len(row.(*testdata.User).Posts))
+		}
+		if fmt.Sprint(counts) != "[2 1 0]" {
+			t.Fatalf("Unexpected Posts per User: %v", counts)
+		}
+		if posts := rows[0].(*testdata.User).Posts; posts[0].PostId != 1 || posts[1].PostId != 2 {
+			t.Fatalf("Unexpected Posts: %v", posts)
+		}
+
+		posts, err := blackvice.Query[testdata.Post](db).Preload("User.Posts").All(ctx)
+		if err != nil {
+			t.Fatalf("Read Posts failed: %v", err)
+		}
+		for _, post := range posts {
+			if post.User == nil || post.User.UserId != post.UserId {
+				t.Fatalf("Post must belong to its User: %v", post.User)
+			}
+		}
+		if len(posts[0].User.Posts) != 2 {
+			t.Fatalf("Nested association must be loaded: %v", posts[0].User.Posts)
+		}
+
+		_, err = db.Relation(&testdata.User{}).Preload("Comments").All(ctx)
+		if spanner.ErrCode(err) != codes.InvalidArgument {
+			t.Fatalf("Expected InvalidArgument, but %v", err)
+		}
 	})
 }
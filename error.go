@@ -2,8 +2,10 @@ package blackvice
 
 import (
 	"fmt"
+	"strings"
 
 	"cloud.google.com/go/spanner"
+	"github.com/pkg/errors"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -12,6 +14,51 @@ func IsErrNotFound(err error) bool {
 	return spanner.ErrCode(err) == codes.NotFound
 }
 
+// ErrStaleObject is returned when a Versioned model was changed or deleted
+// by someone else since it was loaded.
+type ErrStaleObject struct {
+	Table string
+	Key   spanner.Key
+}
+
+func (e *ErrStaleObject) Error() string {
+	return fmt.Sprintf("stale object(Table: %v, Key: %v)", e.Table, e.Key)
+}
+
+func IsErrStaleObject(err error) bool {
+	var stale *ErrStaleObject
+	return errors.As(err, &stale)
+}
+
+// ValidationError lists every field of a model failing validation.
+type ValidationError struct {
+	Table  string
+	Fields []FieldError
+}
+
+type FieldError struct {
+	Column  string
+	Rule    string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	var msgs []string
+	for _, f := range e.Fields {
+		msgs = append(msgs, fmt.Sprintf("%s %s", f.Column, f.Message))
+	}
+	return fmt.Sprintf("invalid %s: %s", e.Table, strings.Join(msgs, ", "))
+}
+
+func IsErrValidation(err error) bool {
+	var invalid *ValidationError
+	return errors.As(err, &invalid)
+}
+
+func errStaleObject(model Model) error {
+	return &ErrStaleObject{Table: model.Table(), Key: model.SpannerKey()}
+}
+
 func errRowNotFound(table string, query string) error {
 	msg := fmt.Sprintf("row not found(Table: %v, Query: %v)", table, query)
 	wrapped := status.Error(codes.NotFound, msg)
@@ -19,9 +66,58 @@ func errRowNotFound(table string, query string) error {
 	return spanner.ToSpannerError(wrapped)
 }
 
+func errKeyNotFound(table string, key spanner.Key) error {
+	msg := fmt.Sprintf("row not found(Table: %v, Key: %v)", table, key)
+	wrapped := status.Error(codes.NotFound, msg)
+
+	return spanner.ToSpannerError(wrapped)
+}
+
 func errMultipleRowsFound(table string, query string) error {
 	msg := fmt.Sprintf("more than one row found(Table: %v, Query: %v)", table, query)
 	wrapped := status.Error(codes.FailedPrecondition, msg)
 
 	return spanner.ToSpannerError(wrapped)
 }
+
+func errInvalidOrder(reason string) error {
+	msg := fmt.Sprintf("invalid order(%v)", reason)
+	wrapped := status.Error(codes.InvalidArgument, msg)
+
+	return spanner.ToSpannerError(wrapped)
+}
+
+func errInvalidCursor(reason string) error {
+	msg := fmt.Sprintf("invalid cursor(%v)", reason)
+	wrapped := status.Error(codes.InvalidArgument, msg)
+
+	return spanner.ToSpannerError(wrapped)
+}
+
+func errTenantViolation(reason string) error {
+	msg := fmt.Sprintf("tenant violation(%v)", reason)
+	wrapped := status.Error(codes.PermissionDenied, msg)
+
+	return spanner.ToSpannerError(wrapped)
+}
+
+func errVersionedMutation(table string) error {
+	msg := fmt.Sprintf("mutations cannot check the lock version of %v, use a ReadWriteTransaction", table)
+	wrapped := status.Error(codes.FailedPrecondition, msg)
+
+	return spanner.ToSpannerError(wrapped)
+}
+
+func errInvalidAssociation(reason string) error {
+	msg := fmt.Sprintf("invalid association(%v)", reason)
+	wrapped := status.Error(codes.InvalidArgument, msg)
+
+	return spanner.ToSpannerError(wrapped)
+}
+
+func errInvalidScope(reason string) error {
+	msg := fmt.Sprintf("invalid scope(%v)", reason)
+	wrapped := status.Error(codes.InvalidArgument, msg)
+
+	return spanner.ToSpannerError(wrapped)
+}
@@ -0,0 +1,297 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"cloud.google.com/go/spanner/spansql"
+	"github.com/pkg/errors"
+)
+
+type Options struct {
+	Package string
+	// Structs maps table names to struct names, overriding the singular
+	// form guessed from the table name.
+	Structs map[string]string
+}
+
+type table struct {
+	Name        string
+	Struct      string
+	Receiver    string
+	Parent      string
+	Fields      []field
+	PrimaryKeys []field
+}
+
+type field struct {
+	Column string
+	Name   string
+	Type   string
+}
+
+// Generate parses CREATE TABLE statements in ddl and returns Go source of
+// structs implementing blackvice.Model. Other statements are ignored.
+func Generate(ddl string, opts Options) ([]byte, error) {
+	parsed, err := spansql.ParseDDL("schema", ddl)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse DDL")
+	}
+
+	pkg := opts.Package
+	if pkg == "" {
+		pkg = "models"
+	}
+
+	var tables []table
+	imports := map[string]bool{
+		"cloud.google.com/go/spanner": true,
+	}
+
+	for _, stmt := range parsed.List {
+		ct, ok := stmt.(*spansql.CreateTable)
+		if !ok {
+			continue
+		}
+
+		t, err := buildTable(ct, opts.Structs, imports)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, t)
+	}
+
+	var std, others []string
+	for path := range imports {
+		if strings.Contains(strings.Split(path, "/")[0], ".") {
+			others = append(others, path)
+		} else {
+			std = append(std, path)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(others)
+
+	var buf bytes.Buffer
+	err = fileTemplate.Execute(&buf, map[string]interface{}{
+		"Package":    pkg,
+		"StdImports": std,
+		"Imports":    others,
+		"Tables":     tables,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to format generated code")
+	}
+
+	return src, nil
+}
+
+func buildTable(ct *spansql.CreateTable, structs map[string]string, imports map[string]bool) (table, error) {
+	name := string(ct.Name)
+	structName, ok := structs[name]
+	if !ok {
+		structName = singularize(camelize(name))
+	}
+
+	t := table{
+		Name:     name,
+		Struct:   structName,
+		Receiver: strings.ToLower(structName[:1]),
+	}
+	if ct.Interleave != nil {
+		t.Parent = string(ct.Interleave.Parent)
+	}
+
+	fields := map[string]field{}
+	for _, col := range ct.Columns {
+		typ, pkg, err := goType(col)
+		if err != nil {
+			return table{}, errors.Wrapf(err, "table %s", name)
+		}
+		if pkg != "" {
+			imports[pkg] = true
+		}
+
+		f := field{
+			Column: string(col.Name),
+			Name:   camelize(string(col.Name)),
+			Type:   typ,
+		}
+		t.Fields = append(t.Fields, f)
+		fields[f.Column] = f
+	}
+
+	for _, part := range ct.PrimaryKey {
+		f, ok := fields[string(part.Column)]
+		if !ok {
+			return table{}, errors.Errorf("table %s: primary key column %s is not defined", name, part.Column)
+		}
+		t.PrimaryKeys = append(t.PrimaryKeys, f)
+	}
+
+	return t, nil
+}
+
+// goType returns the Go type of col and the package it needs. Spanner cannot
+// declare array elements NOT NULL, so they always use the spanner.Null*
+// types, while NOT NULL on an array column only applies to the array itself.
+func goType(col spansql.ColumnDef) (string, string, error) {
+	nullable := !col.NotNull || col.Type.Array
+
+	var typ, pkg string
+	switch col.Type.Base {
+	case spansql.Bool:
+		typ = nullOr(nullable, "bool", "spanner.NullBool")
+	case spansql.Int64:
+		typ = nullOr(nullable, "int64", "spanner.NullInt64")
+	case spansql.Float64:
+		typ = nullOr(nullable, "float64", "spanner.NullFloat64")
+	case spansql.Numeric:
+		typ = nullOr(nullable, "big.Rat", "spanner.NullNumeric")
+		if !nullable {
+			pkg = "math/big"
+		}
+	case spansql.String:
+		typ = nullOr(nullable, "string", "spanner.NullString")
+	case spansql.Bytes:
+		typ = "[]byte"
+	case spansql.Date:
+		typ = nullOr(nullable, "civil.Date", "spanner.NullDate")
+		if !nullable {
+			pkg = "cloud.google.com/go/civil"
+		}
+	case spansql.Timestamp:
+		typ = nullOr(nullable, "time.Time", "spanner.NullTime")
+		if !nullable {
+			pkg = "time"
+		}
+	case spansql.JSON:
+		typ = "spanner.NullJSON"
+	default:
+		return "", "", errors.Errorf("unsupported type of column %s", col.Name)
+	}
+
+	if col.Type.Array {
+		typ = "[]" + typ
+	}
+
+	return typ, pkg, nil
+}
+
+func nullOr(nullable bool, typ, nullType string) string {
+	if nullable {
+		return nullType
+	}
+	return typ
+}
+
+func camelize(s string) string {
+	var sb strings.Builder
+	upper := true
+	for _, r := range s {
+		if r == '_' || r == '-' || r == ' ' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+
+	name := sb.String()
+	if name == "" || !unicode.IsLetter(rune(name[0])) {
+		name = "T" + name
+	}
+	return name
+}
+
+// singularize guesses the singular of an English plural, keeping words
+// ending in -ss, -us and -is such as status or analysis. Irregular names
+// like news need Options.Structs.
+func singularize(s string) string {
+	switch {
+	case strings.HasSuffix(s, "ies") && len(s) > 3:
+		return s[:len(s)-3] + "y"
+	case strings.HasSuffix(s, "sses"), strings.HasSuffix(s, "xes"):
+		return s[:len(s)-2]
+	case strings.HasSuffix(s, "ss"), strings.HasSuffix(s, "us"), strings.HasSuffix(s, "is"):
+		return s
+	case strings.HasSuffix(s, "s") && len(s) > 1:
+		return s[:len(s)-1]
+	}
+	return s
+}
+
+var fileTemplate = template.Must(template.New("file").Parse(fmt.Sprintf(`// Code generated by blackvice gen. DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+{{- range .StdImports }}
+	"{{ . }}"
+{{- end }}
+{{ range .Imports }}
+	"{{ . }}"
+{{- end }}
+)
+{{ range .Tables }}
+{{- $r := .Receiver }}
+type {{ .Struct }} struct {
+{{- range .Fields }}
+	{{ .Name }} {{ .Type }} %[1]sspanner:"{{ .Column }}"%[1]s
+{{- end }}
+}
+
+func ({{ $r }} *{{ .Struct }}) Table() string {
+	return "{{ .Name }}"
+}
+{{ if .Parent }}
+func ({{ $r }} *{{ .Struct }}) ParentTable() string {
+	return "{{ .Parent }}"
+}
+{{ end }}
+func ({{ $r }} *{{ .Struct }}) Params() map[string]interface{} {
+	return map[string]interface{}{
+	{{- range .Fields }}
+		"{{ .Column }}": {{ $r }}.{{ .Name }},
+	{{- end }}
+	}
+}
+
+func ({{ $r }} *{{ .Struct }}) Columns() []string {
+	return []string{
+	{{- range .Fields }}
+		"{{ .Column }}",
+	{{- end }}
+	}
+}
+
+func ({{ $r }} *{{ .Struct }}) SpannerKey() spanner.Key {
+	return spanner.Key{ {{- range $i, $f := .PrimaryKeys }}{{ if $i }}, {{ end }}{{ $r }}.{{ $f.Name }}{{ end -}} }
+}
+
+func ({{ $r }} *{{ .Struct }}) PrimaryKeys() map[string]interface{} {
+	return map[string]interface{}{
+	{{- range .PrimaryKeys }}
+		"{{ .Column }}": {{ $r }}.{{ .Name }},
+	{{- end }}
+	}
+}
+
+func ({{ $r }} *{{ .Struct }}) PrimaryKeyColumns() []string {
+	return []string{ {{- range $i, $f := .PrimaryKeys }}{{ if $i }}, {{ end }}"{{ $f.Column }}"{{ end -}} }
+}
+{{ end -}}
+`, "`")))
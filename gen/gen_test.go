@@ -0,0 +1,67 @@
+package gen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yuemori/blackvice/gen"
+	"github.com/yuemori/blackvice/testdata"
+)
+
+func TestGenerate(t *testing.T) {
+	ddl := strings.Join(append(testdata.CreateTableStatements,
+		"CREATE TABLE user_posts (`UserId` STRING(36) NOT NULL, `PostId` INT64 NOT NULL, `Body` STRING(MAX) NOT NULL, `Tags` ARRAY<STRING(MAX)>, `Scores` ARRAY<INT64> NOT NULL, `PublishedOn` DATE NOT NULL) PRIMARY KEY (`UserId`, `PostId` DESC), INTERLEAVE IN PARENT users ON DELETE CASCADE",
+	), ";\n")
+
+	src, err := gen.Generate(ddl, gen.Options{Package: "models"})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	code := string(src)
+
+	expected := []string{
+		"package models",
+		`"cloud.google.com/go/civil"`,
+		"type User struct {",
+		"UserId    string             `spanner:\"UserId\"`",
+		"Name      spanner.NullString `spanner:\"Name\"`",
+		"Age       spanner.NullInt64  `spanner:\"Age\"`",
+		"CreatedAt spanner.NullTime   `spanner:\"CreatedAt\"`",
+		"func (u *User) Table() string {\n\treturn \"users\"\n}",
+		"type UserPost struct {",
+		"Tags        []spanner.NullString `spanner:\"Tags\"`",
+		"Scores      []spanner.NullInt64  `spanner:\"Scores\"`",
+		"PublishedOn civil.Date           `spanner:\"PublishedOn\"`",
+		"func (u *UserPost) ParentTable() string {\n\treturn \"users\"\n}",
+		"return spanner.Key{u.UserId, u.PostId}",
+		`return []string{"UserId", "PostId"}`,
+		"return []string{\n\t\t\"UserId\",\n\t\t\"PostId\",\n\t\t\"Body\",",
+	}
+	for _, e := range expected {
+		if !strings.Contains(code, e) {
+			t.Errorf("generated code must contain %q:\n%s", e, code)
+		}
+	}
+	if strings.Contains(code, `"time"`) {
+		t.Errorf("generated code must not import unused packages:\n%s", code)
+	}
+}
+
+func TestGenerateStructNames(t *testing.T) {
+	ddl := strings.Join([]string{
+		"CREATE TABLE status (`Id` INT64 NOT NULL) PRIMARY KEY (`Id`)",
+		"CREATE TABLE news (`Id` INT64 NOT NULL) PRIMARY KEY (`Id`)",
+	}, ";\n")
+
+	src, err := gen.Generate(ddl, gen.Options{Package: "models", Structs: map[string]string{"news": "Article"}})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	code := string(src)
+
+	for _, e := range []string{"type Status struct {", "type Article struct {", "return \"news\""} {
+		if !strings.Contains(code, e) {
+			t.Errorf("generated code must contain %q:\n%s", e, code)
+		}
+	}
+}
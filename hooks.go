@@ -0,0 +1,83 @@
+package blackvice
+
+import "context"
+
+// Models may implement any of the hook interfaces below. Before hooks run
+// before the write is built, so fields they set are written. An error from
+// a hook of ReadWriteTx aborts the write and the transaction.
+// Mutation runs the Before hooks when a write is added, and an error from
+// them fails Apply without writing anything. Its After hooks run once the
+// writes are committed, so Apply returns their error for committed writes.
+// InsertOrUpdate runs the insert hooks.
+
+type BeforeInserter interface {
+	BeforeInsert(ctx context.Context) error
+}
+
+type AfterInserter interface {
+	AfterInsert(ctx context.Context) error
+}
+
+type BeforeUpdater interface {
+	BeforeUpdate(ctx context.Context) error
+}
+
+type AfterUpdater interface {
+	AfterUpdate(ctx context.Context) error
+}
+
+type BeforeDeleter interface {
+	BeforeDelete(ctx context.Context) error
+}
+
+type AfterDeleter interface {
+	AfterDelete(ctx context.Context) error
+}
+
+// AfterFinder is called for every model decoded by Find, the Find* reads and
+// queries of a Relation.
+type AfterFinder interface {
+	AfterFind(ctx context.Context) error
+}
+
+func beforeInsert(ctx context.Context, model Model) error {
+	if h, ok := model.(BeforeInserter); ok {
+		return h.BeforeInsert(ctx)
+	}
+	return nil
+}
+
+func afterInsert(ctx context.Context, model Model) error {
+	if h, ok := model.(AfterInserter); ok {
+		return h.AfterInsert(ctx)
+	}
+	return nil
+}
+
+func beforeUpdate(ctx context.Context, model Model) error {
+	if h, ok := model.(BeforeUpdater); ok {
+		return h.BeforeUpdate(ctx)
+	}
+	return nil
+}
+
+func afterUpdate(ctx context.Context, model Model) error {
+	if h, ok := model.(AfterUpdater); ok {
+		return h.AfterUpdate(ctx)
+	}
+	return nil
+}
+
+func beforeDelete(ctx context.Context, model Model) error {
+	if h, ok := model.(BeforeDeleter); ok {
+		return h.BeforeDelete(ctx)
+	}
+	return nil
+}
+
+func afterDelete(ctx context.Context, model Model) error {
+	if h, ok := model.(AfterDeleter); ok {
+		return h.AfterDelete(ctx)
+	}
+	return nil
+}
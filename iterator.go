@@ -0,0 +1,72 @@
+package blackvice
+
+import (
+	"cloud.google.com/go/spanner"
+	"github.com/pkg/errors"
+	"google.golang.org/api/iterator"
+)
+
+// ErrBreak can be returned from an Each callback to stop iterating without
+// an error.
+var ErrBreak = errors.New("break iteration")
+
+type ModelIterator struct {
+	iter    *spanner.RowIterator
+	decode  func(*spanner.Row) (Model, error)
+	err     error
+	stopped bool
+}
+
+// Next returns iterator.Done when there are no more rows.
+func (it *ModelIterator) Next() (Model, error) {
+	if it.err != nil {
+		return nil, it.err
+	}
+
+	row, err := it.iter.Next()
+	if err != nil {
+		return nil, it.fail(err)
+	}
+
+	model, err := it.decode(row)
+	if err != nil {
+		return nil, it.fail(err)
+	}
+
+	return model, nil
+}
+
+func (it *ModelIterator) Stop() {
+	if it.stopped || it.iter == nil {
+		return
+	}
+	it.stopped = true
+	it.iter.Stop()
+}
+
+func (it *ModelIterator) fail(err error) error {
+	it.err = err
+	it.Stop()
+	return err
+}
+
+func eachRow(iter *spanner.RowIterator, fn func(*spanner.Row) error) error {
+	defer iter.Stop()
+
+	for {
+		row, err := iter.Next()
+		if err == iterator.Done {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := fn(row); err != nil {
+			if errors.Is(err, ErrBreak) {
+				return nil
+			}
+			return err
+		}
+	}
+}
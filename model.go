@@ -1,6 +1,13 @@
 package blackvice
 
-import "cloud.google.com/go/spanner"
+import (
+	"reflect"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/spanner"
+	"github.com/pkg/errors"
+)
 
 type Model interface {
 	Table() string
@@ -8,3 +15,100 @@ type Model interface {
 	Params() map[string]interface{}
 	PrimaryKeys() map[string]interface{}
 }
+
+// KeyOrderer is implemented by models that know the order of their primary
+// key columns, which must match the order of SpannerKey. Without it the
+// columns are sorted by name.
+type KeyOrderer interface {
+	PrimaryKeyColumns() []string
+}
+
+// ColumnOrderer is implemented by models that know the schema order of
+// their columns. Without it the columns of Params are sorted by name.
+type ColumnOrderer interface {
+	Columns() []string
+}
+
+func modelType(model Model) reflect.Type {
+	return reflect.TypeOf(model).Elem()
+}
+
+func newModel(rt reflect.Type) (Model, error) {
+	val, ok := reflect.New(rt).Interface().(Model)
+	if !ok {
+		return nil, errors.New("Internal error occurred")
+	}
+	return val, nil
+}
+
+func modelColumns(model Model) []string {
+	if o, ok := model.(ColumnOrderer); ok {
+		return o.Columns()
+	}
+
+	var columns []string
+	for col := range model.Params() {
+		columns = append(columns, col)
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+func columnValues(model Model) ([]string, []interface{}) {
+	return selectValues(model, modelColumns(model))
+}
+
+func selectValues(model Model, cols []string) ([]string, []interface{}) {
+	params := model.Params()
+
+	values := make([]interface{}, 0, len(cols))
+	for _, col := range cols {
+		values = append(values, params[col])
+	}
+	return cols, values
+}
+
+func primaryKeyColumns(model Model) []string {
+	if o, ok := model.(KeyOrderer); ok {
+		return o.PrimaryKeyColumns()
+	}
+
+	var columns []string
+	for col := range model.PrimaryKeys() {
+		columns = append(columns, col)
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// keyValue returns val with spanner.Null* unwrapped, integers widened to
+// int64 and times in UTC, so key values of different Go types compare equal,
+// and false when it is NULL.
+func keyValue(val interface{}) (interface{}, bool) {
+	if val == nil {
+		return nil, false
+	}
+	rv, null := fieldValue(reflect.ValueOf(val))
+	if null {
+		return nil, false
+	}
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	case reflect.String:
+		return rv.String(), true
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return string(rv.Bytes()), true
+		}
+	}
+	if t, ok := rv.Interface().(time.Time); ok {
+		return t.UTC(), true
+	}
+	return rv.Interface(), true
+}
@@ -12,78 +12,279 @@ type SpannerApplyer interface {
 	Apply(ctx context.Context, ms []*spanner.Mutation, opts ...spanner.ApplyOption) (commitTimestamp time.Time, err error)
 }
 
+type spannerCommitter interface {
+	ReadWriteTransactionWithOptions(ctx context.Context, f func(context.Context, *spanner.ReadWriteTransaction) error, options spanner.TransactionOptions) (spanner.CommitResponse, error)
+}
+
+type mutationOp int
+
+const (
+	opInsert mutationOp = iota
+	opInsertOrUpdate
+	opUpdate
+	opDelete
+)
+
+type mutationEntry struct {
+	op    mutationOp
+	model Model
+	// cols are the columns of an update, or its changed columns when nil
+	cols []string
+}
+
+// Mutation buffers writes, built from the models when they are added after
+// their Before hooks and validation have run. The hooks get the context of
+// Do, or context.Background() outside of it. Apply fails without writing
+// anything when a hook or validation failed.
 type Mutation struct {
-	ms      []*spanner.Mutation
+	batch   mutationBatch
+	ctx     context.Context
+	err     error
 	mu      sync.RWMutex
 	applyer SpannerApplyer
 }
 
 func NewMutation(applyer SpannerApplyer) *Mutation {
 	return &Mutation{
-		ms:      make([]*spanner.Mutation, 0),
 		applyer: applyer,
 	}
 }
 
 func (m *Mutation) Do(ctx context.Context, fn func(context.Context, Mutator) error) error {
-	if err := fn(ctx, m); err != nil {
+	if err := m.within(ctx, func() error { return fn(ctx, m) }); err != nil {
 		return err
 	}
 	return m.Apply(ctx)
 }
 
 func (m *Mutation) Apply(ctx context.Context) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	_, err := m.applyer.Apply(ctx, m.ms)
-	m.ms = []*spanner.Mutation{}
-
+	_, err := m.ApplyWithResult(ctx, CommitOptions{})
 	return err
 }
 
-func (m *Mutation) Insert(model Model) {
+// ApplyWithResult is Apply returning the commit timestamp and, when requested
+// and the applyer is a *spanner.Client, the commit stats.
+func (m *Mutation) ApplyWithResult(ctx context.Context, opts CommitOptions) (CommitResult, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	var columns []string
-	var values []interface{}
-	for col, val := range model.Params() {
-		columns = append(columns, col)
-		values = append(values, val)
+	b, err := m.batch, m.err
+	m.batch, m.err = mutationBatch{}, nil
+	if err != nil {
+		return CommitResult{}, err
+	}
+
+	resp, err := m.apply(ctx, b.ms, opts)
+	if err != nil {
+		return CommitResult{}, err
 	}
-	m.ms = append(m.ms, spanner.Insert(model.Table(), columns, values))
+
+	result := newCommitResult(resp)
+
+	if err := b.effects.apply(resp.CommitTs); err != nil {
+		return result, err
+	}
+
+	// the writes are committed, so an After hook error cannot undo them
+	for _, e := range b.applied {
+		if err := e.after(ctx); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+func (m *Mutation) apply(ctx context.Context, ms []*spanner.Mutation, opts CommitOptions) (spanner.CommitResponse, error) {
+	if c, ok := m.applyer.(spannerCommitter); ok && opts.ReturnCommitStats {
+		return c.ReadWriteTransactionWithOptions(ctx, func(ctx context.Context, tx *spanner.ReadWriteTransaction) error {
+			return tx.BufferWrite(ms)
+		}, opts.transactionOptions())
+	}
+
+	ts, err := m.applyer.Apply(ctx, ms)
+	return spanner.CommitResponse{CommitTs: ts}, err
+}
+
+func (m *Mutation) Insert(model Model) {
+	m.add(mutationEntry{op: opInsert, model: model})
 }
 
+// Update writes the columns changed since model was loaded when it embeds
+// ChangeTracker, and every column otherwise.
 func (m *Mutation) Update(model Model) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	m.add(mutationEntry{op: opUpdate, model: model})
+}
 
-	var columns []string
-	var values []interface{}
-	for col, val := range model.Params() {
-		columns = append(columns, col)
-		values = append(values, val)
-	}
-	m.ms = append(m.ms, spanner.Update(model.Table(), columns, values))
+func (m *Mutation) UpdateColumns(model Model, cols ...string) {
+	m.add(mutationEntry{op: opUpdate, model: model, cols: cols})
 }
 
 func (m *Mutation) Delete(model Model) {
+	m.add(mutationEntry{op: opDelete, model: model})
+}
+
+func (m *Mutation) InsertOrUpdate(model Model) {
+	m.add(mutationEntry{op: opInsertOrUpdate, model: model})
+}
+
+// within runs fn with ctx as the context of the Before hooks.
+func (m *Mutation) within(ctx context.Context, fn func() error) error {
+	m.mu.Lock()
+	prev := m.ctx
+	m.ctx = ctx
+	m.mu.Unlock()
+
+	defer func() {
+		m.mu.Lock()
+		m.ctx = prev
+		m.mu.Unlock()
+	}()
+
+	return fn()
+}
+
+func (m *Mutation) discard() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	m.ms = append(m.ms, spanner.Delete(model.Table(), model.SpannerKey()))
+	m.batch, m.err = mutationBatch{}, nil
 }
 
-func (m *Mutation) InsertOrUpdate(model Model) {
+func (m *Mutation) add(e mutationEntry) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	var columns []string
-	var values []interface{}
-	for col, val := range model.Params() {
-		columns = append(columns, col)
-		values = append(values, val)
+	if m.err != nil {
+		return
+	}
+
+	if err := modelError(e.model); err != nil {
+		m.err = err
+		return
+	}
+
+	ctx := m.ctx
+	if ctx == nil {
+		ctx = context.Background()
 	}
-	m.ms = append(m.ms, spanner.InsertOrUpdate(model.Table(), columns, values))
+	if err := e.before(ctx); err != nil {
+		m.err = err
+		return
+	}
+	m.err = m.batch.add(e)
+}
+
+// before runs the Before hooks and validates the models to write.
+func (e mutationEntry) before(ctx context.Context) error {
+	switch e.op {
+	case opInsert, opInsertOrUpdate:
+		if err := beforeInsert(ctx, e.model); err != nil {
+			return err
+		}
+	case opUpdate:
+		if err := beforeUpdate(ctx, e.model); err != nil {
+			return err
+		}
+	case opDelete:
+		return beforeDelete(ctx, e.model)
+	}
+	return Validate(e.model)
+}
+
+func (e mutationEntry) after(ctx context.Context) error {
+	switch e.op {
+	case opInsert, opInsertOrUpdate:
+		return afterInsert(ctx, e.model)
+	case opUpdate:
+		return afterUpdate(ctx, e.model)
+	case opDelete:
+		return afterDelete(ctx, e.model)
+	}
+	return nil
+}
+
+type mutationBatch struct {
+	ms      []*spanner.Mutation
+	applied []mutationEntry
+	effects commitEffects
+}
+
+// add refuses to overwrite or delete Versioned models, since mutations cannot
+// check their lock version.
+func (b *mutationBatch) add(e mutationEntry) error {
+	model := e.model
+	if e.op != opInsert && versionColumn(model) != "" {
+		return errVersionedMutation(model.Table())
+	}
+
+	switch e.op {
+	case opInsert:
+		columns, values := columnValues(model)
+		values = b.stampValues(model, columns, values, true)
+		b.ms = append(b.ms, spanner.Insert(model.Table(), columns, values))
+		b.effects.write(model)
+	case opInsertOrUpdate:
+		columns, values := columnValues(model)
+		values = b.stampValues(model, columns, values, false)
+		b.ms = append(b.ms, spanner.InsertOrUpdate(model.Table(), columns, values))
+		b.effects.write(model)
+	case opUpdate:
+		cols := e.cols
+		if cols == nil {
+			cols = changedColumns(model)
+			if len(cols) == 0 {
+				return nil
+			}
+		}
+		b.update(model, cols)
+	case opDelete:
+		if col := softDeleteColumn(model); col != "" {
+			b.softDelete(model, col)
+			break
+		}
+		b.ms = append(b.ms, spanner.Delete(model.Table(), model.SpannerKey()))
+	}
+
+	b.applied = append(b.applied, e)
+	return nil
+}
+
+func (b *mutationBatch) update(model Model, cols []string) {
+	pks := model.PrimaryKeys()
+	createdAt, updatedAt := timestampColumns(model)
+	selected := append([]string{}, primaryKeyColumns(model)...)
+	for _, col := range cols {
+		if _, ok := pks[col]; !ok && col != createdAt && col != updatedAt {
+			selected = append(selected, col)
+		}
+	}
+	if updatedAt != "" {
+		selected = append(selected, updatedAt)
+	}
+
+	columns, values := selectValues(model, selected)
+	values = b.stampValues(model, columns, values, false)
+
+	b.ms = append(b.ms, spanner.Update(model.Table(), columns, values))
+	b.effects.write(model)
+}
+
+// softDelete writes the deletion time with a mutation, which unlike the DML
+// of ReadWriteTx.Delete cannot require the row to be undeleted: it moves the
+// deletion time of a deleted row and fails when the row does not exist.
+func (b *mutationBatch) softDelete(model Model, col string) {
+	columns, values := selectValues(model, primaryKeyColumns(model))
+	columns = append(append([]string{}, columns...), col)
+	values = append(values, spanner.CommitTimestamp)
+
+	b.ms = append(b.ms, spanner.Update(model.Table(), columns, values))
+	b.effects.stamp(model, col)
+	b.effects.write(model)
+}
+
+func (b *mutationBatch) stampValues(model Model, cols []string, values []interface{}, overwrite bool) []interface{} {
+	values, s := stampValues(model, cols, values, overwrite)
+	b.effects.stamp(model, s.cols...)
+	return values
 }
@@ -0,0 +1,45 @@
+package blackvice_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yuemori/blackvice"
+	"github.com/yuemori/blackvice/testdata"
+
+	"cloud.google.com/go/spanner"
+)
+
+type capturingApplyer struct {
+	ms []*spanner.Mutation
+}
+
+func (a *capturingApplyer) Apply(ctx context.Context, ms []*spanner.Mutation, opts ...spanner.ApplyOption) (time.Time, error) {
+	a.ms = ms
+	return time.Now(), nil
+}
+
+func TestMutationSoftDelete(t *testing.T) {
+	applyer := &capturingApplyer{}
+	note := &testdata.Note{Zone: "z", Id: 7}
+
+	m := blackvice.NewMutation(applyer)
+	m.Delete(note)
+	if err := m.Apply(context.Background()); err != nil {
+		t.Fatalf("Delete Note failed: %v", err)
+	}
+
+	if len(applyer.ms) != 1 {
+		t.Fatalf("Expected 1 mutation, but %d", len(applyer.ms))
+	}
+	expected := "columns:[Id Zone DeletedAt] values:[7 z "
+	if got := fmt.Sprintf("%+v", *applyer.ms[0]); !strings.Contains(got, expected) {
+		t.Fatalf("Expected mutation to contain %s, but %s", expected, got)
+	}
+	if !note.DeletedAt.Valid {
+		t.Fatalf("Expected DeletedAt to be set: %v", note.DeletedAt)
+	}
+}
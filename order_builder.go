@@ -2,41 +2,151 @@ package blackvice
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 )
 
+type NullsOrder string
+
+var (
+	NullsFirst NullsOrder = "NULLS FIRST"
+	NullsLast  NullsOrder = "NULLS LAST"
+)
+
+type OrderKey struct {
+	Column    string
+	Direction Direction
+	Nulls     NullsOrder
+}
+
+func Asc(col string) OrderKey {
+	return OrderKey{Column: col, Direction: ASC}
+}
+
+func Desc(col string) OrderKey {
+	return OrderKey{Column: col, Direction: DESC}
+}
+
+func (k OrderKey) WithNulls(nulls NullsOrder) OrderKey {
+	k.Nulls = nulls
+	return k
+}
+
+func (k OrderKey) normalize() (OrderKey, error) {
+	if k.Column == "" {
+		return k, errInvalidOrder("column is empty")
+	}
+
+	switch Direction(strings.ToUpper(string(k.Direction))) {
+	case "", ASC:
+		k.Direction = ASC
+	case DESC:
+		k.Direction = DESC
+	default:
+		return k, errInvalidOrder(fmt.Sprintf("invalid direction %q for %s", k.Direction, k.Column))
+	}
+
+	switch NullsOrder(strings.ToUpper(string(k.Nulls))) {
+	case "":
+		k.Nulls = ""
+	case NullsFirst:
+		k.Nulls = NullsFirst
+	case NullsLast:
+		k.Nulls = NullsLast
+	default:
+		return k, errInvalidOrder(fmt.Sprintf("invalid nulls order %q for %s", k.Nulls, k.Column))
+	}
+
+	return k, nil
+}
+
+func (k OrderKey) build() string {
+	s := fmt.Sprintf("%s %s", quote(k.Column), k.Direction)
+	if k.Nulls != "" {
+		s += " " + string(k.Nulls)
+	}
+	return s
+}
+
+func (p OrderParam) keys() []OrderKey {
+	cols := make([]string, 0, len(p))
+	for col := range p {
+		cols = append(cols, col)
+	}
+	sort.Strings(cols)
+
+	keys := make([]OrderKey, 0, len(cols))
+	for _, col := range cols {
+		keys = append(keys, OrderKey{Column: col, Direction: p[col]})
+	}
+	return keys
+}
+
 type OrderBuilder struct {
-	param OrderParam
+	keys []OrderKey
 }
 
 func (b OrderBuilder) IsEmpty() bool {
-	return len(b.param) == 0
+	return len(b.keys) == 0
 }
 
-func (b OrderBuilder) Merge(other OrderParam) OrderBuilder {
-	param := OrderParam{}
-	for k, v := range b.param {
-		param[k] = v
+func (b OrderBuilder) has(col string) bool {
+	for _, key := range b.keys {
+		if key.Column == col {
+			return true
+		}
 	}
+	return false
+}
+
+func (b OrderBuilder) Keys() []OrderKey {
+	return append([]OrderKey{}, b.keys...)
+}
+
+// OrderParam is a map, so its columns are applied in column name order.
+// Use Append to control the order of multiple keys.
+func (b OrderBuilder) Merge(other OrderParam) OrderBuilder {
+	return b.Append(other.keys()...)
+}
+
+// A key for a column already in the builder replaces it in place; invalid
+// keys are dropped.
+func (b OrderBuilder) Append(keys ...OrderKey) OrderBuilder {
+	merged := append([]OrderKey{}, b.keys...)
+
+	for _, key := range keys {
+		key, err := key.normalize()
+		if err != nil {
+			continue
+		}
 
-	for k, v := range other {
-		param[k] = v
+		replaced := false
+		for i := range merged {
+			if merged[i].Column == key.Column {
+				merged[i] = key
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, key)
+		}
 	}
 
 	return OrderBuilder{
-		param: param,
+		keys: merged,
 	}
 }
 
 func (b OrderBuilder) Build() string {
-	if len(b.param) == 0 {
+	if len(b.keys) == 0 {
 		return ""
 	}
 
 	param := []string{}
 
-	for col, dir := range b.param {
-		param = append(param, fmt.Sprintf("%s %s", col, dir))
+	for _, key := range b.keys {
+		param = append(param, key.build())
 	}
 
 	return fmt.Sprintf("ORDER BY %s", strings.Join(param, ", "))
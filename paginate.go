@@ -0,0 +1,205 @@
+package blackvice
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+type cursorPayload struct {
+	Keys   []string          `json:"k"`
+	Values []json.RawMessage `json:"v"`
+}
+
+// Paginate returns up to pageSize rows after cursor and the cursor of the
+// next page, which is empty on the last page. Rows are ordered by the
+// relation's sort keys, those of its default scope and then the primary
+// keys, so pass an empty cursor for the first page and keep the same
+// Order/OrderBy for following pages.
+func (b *QueryContext) Paginate(ctx context.Context, cursor string, pageSize int) ([]Model, string, error) {
+	q, keys, err := b.page(cursor, pageSize)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rows, err := q.All(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(rows) <= pageSize {
+		return rows, "", nil
+	}
+
+	rows = rows[:pageSize]
+	next, err := b.encodeCursor(keys, rows[pageSize-1])
+	if err != nil {
+		return nil, "", err
+	}
+
+	return rows, next, nil
+}
+
+func (b *QueryContext) page(cursor string, pageSize int) (*QueryContext, []OrderKey, error) {
+	if b.err != nil {
+		return nil, nil, b.err
+	}
+	if pageSize <= 0 {
+		return nil, nil, errInvalidCursor(fmt.Sprintf("page size must be positive: %d", pageSize))
+	}
+	if b.offset != 0 {
+		return nil, nil, errInvalidCursor("Paginate cannot be combined with Offset")
+	}
+
+	q := b.pageOrder()
+	keys := q.orderBuilder.Keys()
+
+	if !q.selectBuilder.IsEmpty() {
+		for _, key := range keys {
+			if !q.selectBuilder.has(key.Column) {
+				return nil, nil, errInvalidCursor(fmt.Sprintf("sort key %s is not selected", key.Column))
+			}
+		}
+	}
+
+	if cursor != "" {
+		values, err := b.decodeCursor(keys, cursor)
+		if err != nil {
+			return nil, nil, err
+		}
+		q = q.Filter(seekCondition(keys, values)).(*QueryContext)
+	}
+
+	return q.Limit(pageSize + 1).(*QueryContext), keys, nil
+}
+
+// pageOrder sorts by the keys of the relation and its default scope, then
+// by the primary keys as a tiebreaker.
+func (b *QueryContext) pageOrder() *QueryContext {
+	r := b.clone()
+	r.orderBuilder = b.order().Append(b.primaryKeyOrder()...)
+	return r
+}
+
+func (b *QueryContext) primaryKeyOrder() []OrderKey {
+	var keys []OrderKey
+	for _, col := range primaryKeyColumns(b.model) {
+		if b.order().has(col) {
+			continue
+		}
+		keys = append(keys, Asc(col))
+	}
+	return keys
+}
+
+func (b *QueryContext) encodeCursor(keys []OrderKey, row Model) (string, error) {
+	params := row.Params()
+
+	payload := cursorPayload{}
+	for _, key := range keys {
+		val, ok := params[key.Column]
+		if !ok {
+			return "", errInvalidCursor(fmt.Sprintf("sort key %s is not a column of %s", key.Column, b.Table()))
+		}
+		raw, err := json.Marshal(val)
+		if err != nil {
+			return "", err
+		}
+		payload.Keys = append(payload.Keys, key.build())
+		payload.Values = append(payload.Values, raw)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func (b *QueryContext) decodeCursor(keys []OrderKey, cursor string) ([]interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errInvalidCursor("malformed cursor")
+	}
+
+	payload := cursorPayload{}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, errInvalidCursor("malformed cursor")
+	}
+	if len(payload.Keys) != len(keys) || len(payload.Values) != len(keys) {
+		return nil, errInvalidCursor("cursor does not match the order of the relation")
+	}
+
+	// decode each value into the Go type the model uses for the column
+	params := b.model.Params()
+	values := make([]interface{}, 0, len(keys))
+
+	for i, key := range keys {
+		if payload.Keys[i] != key.build() {
+			return nil, errInvalidCursor("cursor does not match the order of the relation")
+		}
+
+		rt := reflect.TypeOf(params[key.Column])
+		if rt == nil {
+			return nil, errInvalidCursor(fmt.Sprintf("sort key %s is not a column of %s", key.Column, b.Table()))
+		}
+		ptr := reflect.New(rt)
+		if err := json.Unmarshal(payload.Values[i], ptr.Interface()); err != nil {
+			return nil, errInvalidCursor("malformed cursor")
+		}
+		values = append(values, ptr.Elem().Interface())
+	}
+
+	return values, nil
+}
+
+// seekCondition builds (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ..., with < for
+// descending keys. NULL sorts first for ascending and last for descending
+// keys unless the key says otherwise, so the comparisons take the NULL rows
+// before or after a value into account.
+func seekCondition(keys []OrderKey, values []interface{}) Condition {
+	var alts []Condition
+
+	for i, key := range keys {
+		conds := []Condition{}
+		for j := 0; j < i; j++ {
+			conds = append(conds, seekEqual(keys[j], values[j]))
+		}
+		conds = append(conds, seekAfter(key, values[i]))
+		alts = append(alts, And(conds...))
+	}
+
+	return Or(alts...)
+}
+
+func seekEqual(key OrderKey, value interface{}) Condition {
+	if isNullValue(value) {
+		return IsNull(key.Column)
+	}
+	return Eq(key.Column, value)
+}
+
+// seekAfter matches the rows sorted after value by key.
+func seekAfter(key OrderKey, value interface{}) Condition {
+	nullsFirst := key.Nulls == NullsFirst || key.Nulls == "" && key.Direction != DESC
+
+	if isNullValue(value) {
+		if nullsFirst {
+			return IsNotNull(key.Column)
+		}
+		return Or()
+	}
+
+	var after Condition
+	if key.Direction == DESC {
+		after = Lt(key.Column, value)
+	} else {
+		after = Gt(key.Column, value)
+	}
+	if nullsFirst {
+		return after
+	}
+	return Or(after, IsNull(key.Column))
+}
@@ -0,0 +1,32 @@
+package blackvice_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yuemori/blackvice"
+	"github.com/yuemori/blackvice/testdata"
+
+	"cloud.google.com/go/spanner"
+	"google.golang.org/grpc/codes"
+)
+
+func TestPaginateInvalid(t *testing.T) {
+	ctx := context.Background()
+	base := blackvice.NewQueryContext(&testdata.User{}, nil)
+
+	tests := []struct {
+		name string
+		rel  blackvice.Relation
+	}{
+		{"offset", base.Offset(2)},
+		{"unselected sort key", base.Select([]string{"Name"}).OrderBy(blackvice.Asc("Name"))},
+	}
+
+	for _, tt := range tests {
+		_, _, err := tt.rel.Paginate(ctx, "", 2)
+		if spanner.ErrCode(err) != codes.InvalidArgument {
+			t.Errorf("%s: expected InvalidArgument, but %v", tt.name, err)
+		}
+	}
+}
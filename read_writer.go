@@ -17,6 +17,7 @@ type SpannerReadWriter interface {
 type ReadWriteTx struct {
 	tx      SpannerReadWriter
 	builder StatementBuilder
+	effects commitEffects
 }
 
 func NewReadWriteTx(tx SpannerReadWriter) *ReadWriteTx {
@@ -35,7 +36,33 @@ func (rw *ReadWriteTx) Find(ctx context.Context, model Model) error {
 	return rw.Reader().Find(ctx, model)
 }
 
+func (rw *ReadWriteTx) FindAll(ctx context.Context, models []Model) ([]Model, error) {
+	return rw.Reader().FindAll(ctx, models)
+}
+
+func (rw *ReadWriteTx) FindByKeys(ctx context.Context, model Model, keys ...spanner.Key) ([]Model, error) {
+	return rw.Reader().FindByKeys(ctx, model, keys...)
+}
+
+func (rw *ReadWriteTx) FindByKeyRange(ctx context.Context, model Model, keyRange spanner.KeyRange) ([]Model, error) {
+	return rw.Reader().FindByKeyRange(ctx, model, keyRange)
+}
+
+func (rw *ReadWriteTx) ReadUsingIndex(ctx context.Context, model Model, index string, keys spanner.KeySet) ([]Model, error) {
+	return rw.Reader().ReadUsingIndex(ctx, model, index, keys)
+}
+
 func (rw *ReadWriteTx) Insert(ctx context.Context, target Model) error {
+	if err := modelError(target); err != nil {
+		return err
+	}
+	if err := beforeInsert(ctx, target); err != nil {
+		return err
+	}
+	if err := Validate(target); err != nil {
+		return err
+	}
+
 	cnt, err := rw.tx.Update(ctx, rw.builder.Insert(target))
 	if err != nil {
 		return err
@@ -45,31 +72,127 @@ func (rw *ReadWriteTx) Insert(ctx context.Context, target Model) error {
 		return errors.Errorf("Failed to insert %v", target)
 	}
 
-	return nil
+	createdAt, updatedAt := timestampColumns(target)
+	rw.effects.stamp(target, createdAt, updatedAt)
+	rw.effects.write(target)
+
+	return afterInsert(ctx, target)
 }
 
+// Update writes the columns changed since target was loaded when it embeds
+// ChangeTracker, and every column otherwise.
 func (rw *ReadWriteTx) Update(ctx context.Context, target Model) error {
-	cnt, err := rw.tx.Update(ctx, rw.builder.Update(target))
+	if err := modelError(target); err != nil {
+		return err
+	}
+	if err := beforeUpdate(ctx, target); err != nil {
+		return err
+	}
+	if err := Validate(target); err != nil {
+		return err
+	}
+
+	cols := changedColumns(target)
+	if len(cols) == 0 || emptyUpdate(target, cols) {
+		return nil
+	}
+
+	if err := rw.updateColumns(ctx, target, cols); err != nil {
+		return err
+	}
+
+	return afterUpdate(ctx, target)
+}
+
+func (rw *ReadWriteTx) UpdateColumns(ctx context.Context, target Model, cols ...string) error {
+	if err := modelError(target); err != nil {
+		return err
+	}
+	if err := beforeUpdate(ctx, target); err != nil {
+		return err
+	}
+	if err := Validate(target); err != nil {
+		return err
+	}
+
+	if emptyUpdate(target, cols) {
+		return nil
+	}
+
+	if err := rw.updateColumns(ctx, target, cols); err != nil {
+		return err
+	}
+
+	return afterUpdate(ctx, target)
+}
+
+// updateColumns leaves the new lock version and the snapshot to the commit, so
+// the model is unchanged when the transaction fails.
+func (rw *ReadWriteTx) updateColumns(ctx context.Context, target Model, cols []string) error {
+	version := versionColumn(target)
+	var lock, next interface{}
+	if version != "" {
+		lock = rw.effects.version(target, version)
+		v, err := nextVersion(lock)
+		if err != nil {
+			return err
+		}
+		next = v
+	}
+
+	cnt, err := rw.tx.Update(ctx, rw.builder.updateColumns(target, cols, lock))
 	if err != nil {
 		return err
 	}
 
 	if cnt == 0 {
+		if version != "" {
+			return errStaleObject(target)
+		}
 		return errors.Errorf("Failed to update %v", target)
 	}
 
+	if version != "" {
+		rw.effects.bump(target, version, next)
+	}
+
+	_, updatedAt := timestampColumns(target)
+	rw.effects.stamp(target, updatedAt)
+	rw.effects.write(target)
+
 	return nil
 }
 
 func (rw *ReadWriteTx) Delete(ctx context.Context, target Model) error {
-	cnt, err := rw.tx.Update(ctx, rw.builder.Delete(target))
+	if err := modelError(target); err != nil {
+		return err
+	}
+	if err := beforeDelete(ctx, target); err != nil {
+		return err
+	}
+
+	version := versionColumn(target)
+	var lock interface{}
+	if version != "" {
+		lock = rw.effects.version(target, version)
+	}
+
+	cnt, err := rw.tx.Update(ctx, rw.builder.delete(target, lock))
 	if err != nil {
 		return err
 	}
 
 	if cnt == 0 {
+		if version != "" {
+			return errStaleObject(target)
+		}
 		return errors.Errorf("Failed to delete %v", target)
 	}
 
-	return nil
+	if col := softDeleteColumn(target); col != "" {
+		rw.effects.stamp(target, col)
+		rw.effects.write(target)
+	}
+
+	return afterDelete(ctx, target)
 }
@@ -0,0 +1,43 @@
+package blackvice_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yuemori/blackvice"
+	"github.com/yuemori/blackvice/testdata"
+
+	"cloud.google.com/go/spanner"
+)
+
+type recordingReadWriter struct {
+	blackvice.SpannerReader
+	stmts []spanner.Statement
+}
+
+func (rw *recordingReadWriter) Update(ctx context.Context, stmt spanner.Statement) (int64, error) {
+	rw.stmts = append(rw.stmts, stmt)
+	return 1, nil
+}
+
+func TestUpdateColumnsWithoutColumns(t *testing.T) {
+	tx := &recordingReadWriter{}
+	rw := blackvice.NewReadWriteTx(tx)
+	note := &testdata.Note{Zone: "z", Id: 7}
+
+	for _, cols := range [][]string{nil, {"Zone", "Id"}} {
+		if err := rw.UpdateColumns(context.Background(), note, cols...); err != nil {
+			t.Fatalf("UpdateColumns failed: %v", err)
+		}
+	}
+	if len(tx.stmts) != 0 {
+		t.Fatalf("Expected no statement, but %v", tx.stmts)
+	}
+
+	if err := rw.UpdateColumns(context.Background(), note, "Text"); err != nil {
+		t.Fatalf("UpdateColumns failed: %v", err)
+	}
+	if len(tx.stmts) != 1 {
+		t.Fatalf("Expected 1 statement, but %v", tx.stmts)
+	}
+}
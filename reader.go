@@ -2,6 +2,7 @@ package blackvice
 
 import (
 	"context"
+	"reflect"
 
 	"cloud.google.com/go/spanner"
 )
@@ -14,27 +15,215 @@ type SpannerReader interface {
 }
 
 type ReadTx struct {
-	tx SpannerReader
+	txFn func() SpannerReader
+	// snapshot returns the transaction for reads which must see the same
+	// data, and a function to release it.
+	snapshot func() (SpannerReader, func())
 }
 
 func NewReadTx(tx SpannerReader) *ReadTx {
-	return &ReadTx{tx: tx}
+	return &ReadTx{
+		txFn:     func() SpannerReader { return tx },
+		snapshot: func() (SpannerReader, func()) { return tx, func() {} },
+	}
 }
 
 func (r *ReadTx) Relation(model Model) *QueryContext {
-	return NewQueryContext(model, r.tx)
+	return newQueryContext(model, r.txFn)
 }
 
 func (r *ReadTx) Find(ctx context.Context, model Model) error {
-	var columns []string
-	for col := range model.Params() {
-		columns = append(columns, col)
+	if err := modelError(model); err != nil {
+		return err
+	}
+	row, err := r.txFn().ReadRow(ctx, model.Table(), model.SpannerKey(), modelColumns(model))
+	if err != nil {
+		return err
 	}
 
-	row, err := r.tx.ReadRow(ctx, model.Table(), model.SpannerKey(), columns)
+	deleted, err := rowSoftDeleted(row, model)
 	if err != nil {
 		return err
 	}
+	if deleted {
+		return errKeyNotFound(model.Table(), model.SpannerKey())
+	}
+
+	return decodeModel(ctx, row, model)
+}
+
+// FindByKeys reads the rows of model's table for keys with the Read API.
+// Missing and soft deleted keys are skipped.
+func (r *ReadTx) FindByKeys(ctx context.Context, model Model, keys ...spanner.Key) ([]Model, error) {
+	return r.read(ctx, model, spanner.KeySetFromKeys(keys...))
+}
+
+func (r *ReadTx) FindByKeyRange(ctx context.Context, model Model, keyRange spanner.KeyRange) ([]Model, error) {
+	return r.read(ctx, model, keyRange)
+}
+
+// ReadUsingIndex looks up the primary keys for keys in a secondary index and
+// returns the full rows in index order, so the index does not need to store
+// every column of the model. Both reads run in one transaction, a read-only
+// one for the Reader of a DB.
+func (r *ReadTx) ReadUsingIndex(ctx context.Context, model Model, index string, keys spanner.KeySet) ([]Model, error) {
+	if err := modelError(model); err != nil {
+		return nil, err
+	}
+	rt := modelType(model)
+
+	tx, release := r.snapshot()
+	defer release()
+
+	iter := tx.ReadWithOptions(ctx, model.Table(), keys, primaryKeyColumns(model), &spanner.ReadOptions{Index: index})
+	indexed, err := decodeRows(iter, rt)
+	if err != nil {
+		return nil, err
+	}
+	if len(indexed) == 0 {
+		return []Model{}, nil
+	}
+
+	var pks []spanner.Key
+	for _, m := range indexed {
+		pks = append(pks, m.SpannerKey())
+	}
+
+	rows, err := readRows(ctx, tx, model, spanner.KeySetFromKeys(pks...))
+	if err != nil {
+		return nil, err
+	}
+
+	byKey := map[string]Model{}
+	for _, row := range rows {
+		byKey[row.SpannerKey().String()] = row
+	}
+
+	res := []Model{}
+	for _, pk := range pks {
+		if row, ok := byKey[pk.String()]; ok {
+			res = append(res, row)
+		}
+	}
+
+	return res, nil
+}
+
+// FindAll fills models in place with one Read per table and returns the
+// models whose rows were not found.
+func (r *ReadTx) FindAll(ctx context.Context, models []Model) ([]Model, error) {
+	var tables []string
+	groups := map[string][]Model{}
+	for _, model := range models {
+		if err := modelError(model); err != nil {
+			return nil, err
+		}
+		table := model.Table()
+		if _, ok := groups[table]; !ok {
+			tables = append(tables, table)
+		}
+		groups[table] = append(groups[table], model)
+	}
+
+	found := map[Model]bool{}
+
+	for _, table := range tables {
+		group := groups[table]
+
+		var keys []spanner.Key
+		byKey := map[string][]Model{}
+		for _, model := range group {
+			key := model.SpannerKey()
+			if _, ok := byKey[key.String()]; !ok {
+				keys = append(keys, key)
+			}
+			byKey[key.String()] = append(byKey[key.String()], model)
+		}
+
+		rt := modelType(group[0])
+		iter := r.txFn().Read(ctx, table, spanner.KeySetFromKeys(keys...), modelColumns(group[0]))
+
+		err := eachRow(iter, func(row *spanner.Row) error {
+			if deleted, err := rowSoftDeleted(row, group[0]); err != nil || deleted {
+				return err
+			}
+
+			val, err := newModel(rt)
+			if err != nil {
+				return err
+			}
+			if err := row.ToStruct(val); err != nil {
+				return err
+			}
+
+			for _, model := range byKey[val.SpannerKey().String()] {
+				if err := decodeModel(ctx, row, model); err != nil {
+					return err
+				}
+				found[model] = true
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	missing := []Model{}
+	for _, model := range models {
+		if !found[model] {
+			missing = append(missing, model)
+		}
+	}
+
+	return missing, nil
+}
+
+func (r *ReadTx) read(ctx context.Context, model Model, keys spanner.KeySet) ([]Model, error) {
+	if err := modelError(model); err != nil {
+		return nil, err
+	}
+	return readRows(ctx, r.txFn(), model, keys)
+}
+
+func readRows(ctx context.Context, tx SpannerReader, model Model, keys spanner.KeySet) ([]Model, error) {
+	iter := tx.Read(ctx, model.Table(), keys, modelColumns(model))
+	rows, err := decodeRows(iter, modelType(model))
+	if err != nil {
+		return nil, err
+	}
+
+	res := []Model{}
+	for _, row := range rows {
+		if isSoftDeleted(row) {
+			continue
+		}
+		if err := afterFind(ctx, row); err != nil {
+			return nil, err
+		}
+		res = append(res, row)
+	}
+
+	return res, nil
+}
+
+func decodeRows(iter *spanner.RowIterator, rt reflect.Type) ([]Model, error) {
+	res := []Model{}
+
+	err := eachRow(iter, func(row *spanner.Row) error {
+		val, err := newModel(rt)
+		if err != nil {
+			return err
+		}
+		if err := row.ToStruct(val); err != nil {
+			return err
+		}
+		res = append(res, val)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	return row.ToStruct(model)
+	return res, nil
 }
@@ -3,11 +3,10 @@ package blackvice
 import (
 	"context"
 	"fmt"
+	"math"
 	"reflect"
 
 	"cloud.google.com/go/spanner"
-	"github.com/pkg/errors"
-	"google.golang.org/api/iterator"
 )
 
 type Direction string
@@ -21,19 +20,54 @@ type OrderParam map[string]Direction
 type WhereParam map[string]interface{}
 
 type QueryContext struct {
-	tx            SpannerReader
+	txFn func() SpannerReader
+	// origin is shared by the relations derived from one relation of a
+	// transaction, so scopes cannot swap in another transaction
+	origin        *struct{}
 	selectBuilder SelectBuilder
 	model         Model
+	modelType     reflect.Type
 	index         string
 	limit         int
+	offset        int
 	whereBuilder  WhereBuilder
 	orderBuilder  OrderBuilder
+	unscoped      bool
+	defaultScope  Scope
+	tenant        *tenant
+	preloads      []string
+	err           error
 }
 
 func NewQueryContext(model Model, tx SpannerReader) *QueryContext {
+	return newQueryContext(model, func() SpannerReader { return tx })
+}
+
+// newQueryContext takes a function returning the transaction for each query,
+// so relations of a DB can run every query in a new single-use transaction
+// and be reused.
+func newQueryContext(model Model, txFn func() SpannerReader) *QueryContext {
+	qc := baseQueryContext(model, txFn, &struct{}{})
+	if err := modelError(model); err != nil {
+		qc.err = err
+		return qc
+	}
+	if s, ok := model.(DefaultScoper); ok {
+		qc.defaultScope = s.DefaultScope
+		if qc.defaults() == nil {
+			qc.err = errInvalidScope(fmt.Sprintf("default scope of %s did not return a relation of the query", model.Table()))
+		}
+	}
+	return qc
+}
+
+// baseQueryContext returns a relation of model without its default scope.
+func baseQueryContext(model Model, txFn func() SpannerReader, origin *struct{}) *QueryContext {
 	return &QueryContext{
-		tx:            tx,
+		txFn:          txFn,
+		origin:        origin,
 		model:         model,
+		modelType:     modelType(model),
 		selectBuilder: SelectBuilder{selects: []string{}},
 		whereBuilder:  WhereBuilder{},
 		orderBuilder:  OrderBuilder{},
@@ -42,63 +76,158 @@ func NewQueryContext(model Model, tx SpannerReader) *QueryContext {
 }
 
 func (b *QueryContext) Select(other []string) Relation {
-	r := b
+	r := b.clone()
 	r.selectBuilder = b.selectBuilder.Merge(other)
 	return r
 }
 
 func (b *QueryContext) Where(param WhereParam) Relation {
-	r := b
+	r := b.clone()
 	r.whereBuilder = r.whereBuilder.Merge(param)
 	return r
 }
 
+func (b *QueryContext) Filter(conds ...Condition) Relation {
+	r := b.clone()
+	r.whereBuilder = r.whereBuilder.And(conds...)
+	return r
+}
+
 func (b *QueryContext) Order(param OrderParam) Relation {
-	r := b
-	r.orderBuilder = b.orderBuilder.Merge(param)
+	return b.OrderBy(param.keys()...)
+}
+
+func (b *QueryContext) OrderBy(keys ...OrderKey) Relation {
+	r := b.clone()
+	for _, key := range keys {
+		if _, err := key.normalize(); err != nil && r.err == nil {
+			r.err = err
+		}
+	}
+	r.orderBuilder = b.orderBuilder.Append(keys...)
 	return r
 }
 
 func (b *QueryContext) Limit(limit int) Relation {
-	r := b
+	r := b.clone()
 	r.limit = limit
 	return r
 }
 
+func (b *QueryContext) Offset(offset int) Relation {
+	r := b.clone()
+	r.offset = offset
+	return r
+}
+
 func (b *QueryContext) ForceIndex(index string) Relation {
-	r := b
+	r := b.clone()
 	r.index = index
 	return r
 }
 
+// Unscoped includes soft deleted rows and drops the default scope.
+func (b *QueryContext) Unscoped() Relation {
+	r := b.clone()
+	r.unscoped = true
+	return r
+}
+
+func (b *QueryContext) clone() *QueryContext {
+	r := *b
+	return &r
+}
+
 func (b *QueryContext) Table() string {
 	return b.model.Table()
 }
 
 func (b *QueryContext) SQL() string {
-	index := ""
-	if b.index != "" {
-		index = fmt.Sprintf("{FORCE_INDEX: %s}", b.index)
+	return fmt.Sprintf(
+		"SELECT %s FROM %s %s %s %s",
+		b.selectBuilder.Build(),
+		b.from(),
+		b.where().Build(),
+		b.order().Build(),
+		b.limitClause(),
+	)
+}
+
+func (b *QueryContext) from() string {
+	if b.index == "" {
+		return b.Table()
+	}
+	return fmt.Sprintf("%s{FORCE_INDEX: %s}", b.Table(), b.index)
+}
+
+func (b *QueryContext) limitClause() string {
+	if b.offset != 0 {
+		limit := int64(b.limit)
+		if limit == 0 {
+			// OFFSET is only allowed after LIMIT
+			limit = math.MaxInt64
+		}
+		return fmt.Sprintf("LIMIT %d OFFSET %d", limit, b.offset)
 	}
 
-	limit := ""
 	if b.limit != 0 {
-		limit = fmt.Sprintf("LIMIT %d", b.limit)
+		return fmt.Sprintf("LIMIT %d", b.limit)
 	}
 
-	return fmt.Sprintf(
-		"SELECT %s FROM %s%s %s %s %s",
-		b.selectBuilder.Build(),
-		b.Table(),
-		index,
-		b.whereBuilder.Build(),
-		b.orderBuilder.Build(),
-		limit,
-	)
+	return ""
+}
+
+func (b *QueryContext) Count(ctx context.Context) (int64, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s %s", b.from(), b.where().Build())
+
+	var count int64
+	err := b.query(ctx, query, b.where().Params(), func(row *spanner.Row) error {
+		return row.Column(0, &count)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (b *QueryContext) Exists(ctx context.Context) (bool, error) {
+	if b.err != nil {
+		return false, b.err
+	}
+
+	query := fmt.Sprintf("SELECT 1 FROM %s %s LIMIT 1", b.from(), b.where().Build())
+
+	exists := false
+	err := b.query(ctx, query, b.where().Params(), func(row *spanner.Row) error {
+		exists = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return exists, nil
 }
 
 func (b *QueryContext) All(ctx context.Context) ([]Model, error) {
-	return b.Query(ctx, b.SQL(), b.whereBuilder.Params())
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	rows, err := b.queryModels(ctx, b.SQL(), b.where().Params())
+	if err != nil {
+		return nil, err
+	}
+	if err := b.preload(ctx, rows); err != nil {
+		return nil, err
+	}
+
+	return rows, nil
 }
 
 func (b *QueryContext) FindOne(ctx context.Context) (Model, error) {
@@ -119,58 +248,85 @@ func (b *QueryContext) FindOne(ctx context.Context) (Model, error) {
 }
 
 func (b *QueryContext) Query(ctx context.Context, query string, params map[string]interface{}) ([]Model, error) {
-	stmt := spanner.NewStatement(query)
-	stmt.Params = params
-	iter := b.tx.Query(ctx, stmt)
-
-	rows, err := b.buildRows(iter)
+	params, err := b.bindTenant(query, params)
 	if err != nil {
 		return nil, err
 	}
+	return b.queryModels(ctx, query, params)
+}
 
+func (b *QueryContext) queryModels(ctx context.Context, query string, params map[string]interface{}) ([]Model, error) {
 	res := []Model{}
 
-	for _, row := range rows {
-		rt := reflect.TypeOf(b.model)
-		ptr := reflect.New(rt)
-		rv := reflect.New(rt.Elem())
-		ptr.Elem().Set(rv)
-		if err := row.ToStruct(rv.Interface()); err != nil {
-			return nil, err
-		}
-		val, ok := rv.Interface().(Model)
-		if !ok {
-			return nil, errors.New("Internal error occurred")
+	err := b.query(ctx, query, params, func(row *spanner.Row) error {
+		val, err := b.decode(ctx, row)
+		if err != nil {
+			return err
 		}
 		res = append(res, val)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return res, nil
 }
 
-func (b *QueryContext) buildRows(iter *spanner.RowIterator) ([]*spanner.Row, error) {
-	defer iter.Stop()
-
-	rows := []*spanner.Row{}
+func (b *QueryContext) Each(ctx context.Context, fn func(Model) error) error {
+	if b.err != nil {
+		return b.err
+	}
 
-	for {
-		row, err := iter.Next()
+	return b.query(ctx, b.SQL(), b.where().Params(), func(row *spanner.Row) error {
+		val, err := b.decode(ctx, row)
 		if err != nil {
-			if err == iterator.Done {
-				break
-			}
-			return nil, err
+			return err
 		}
-		rows = append(rows, row)
+		return fn(val)
+	})
+}
+
+func (b *QueryContext) Iter(ctx context.Context) *ModelIterator {
+	if b.err != nil {
+		return &ModelIterator{err: b.err}
 	}
 
-	return rows, nil
+	stmt := spanner.NewStatement(b.SQL())
+	stmt.Params = b.where().Params()
+
+	return &ModelIterator{
+		iter: b.txFn().Query(ctx, stmt),
+		decode: func(row *spanner.Row) (Model, error) {
+			return b.decode(ctx, row)
+		},
+	}
 }
 
-func (b *QueryContext) columns() []string {
-	var columns []string
-	for col := range b.model.Params() {
-		columns = append(columns, col)
+func (b *QueryContext) query(ctx context.Context, query string, params map[string]interface{}, fn func(*spanner.Row) error) error {
+	stmt := spanner.NewStatement(query)
+	stmt.Params = params
+
+	return eachRow(b.txFn().Query(ctx, stmt), fn)
+}
+
+// bindTenant refuses raw queries of a TenantDB relation which do not
+// reference the tenant. Queries built by the relation filter by the tenant
+// already and skip it.
+func (b *QueryContext) bindTenant(query string, params map[string]interface{}) (map[string]interface{}, error) {
+	if b.tenant == nil {
+		return params, nil
+	}
+	return b.tenant.bindQuery(query, params)
+}
+
+func (b *QueryContext) decode(ctx context.Context, row *spanner.Row) (Model, error) {
+	val, err := newModel(b.modelType)
+	if err != nil {
+		return nil, err
+	}
+	if err := decodeModel(ctx, row, val); err != nil {
+		return nil, err
 	}
-	return columns
+	return val, nil
 }
@@ -0,0 +1,112 @@
+package blackvice
+
+import (
+	"context"
+	"reflect"
+
+	"cloud.google.com/go/spanner"
+	"github.com/pkg/errors"
+)
+
+var decoderType = reflect.TypeOf((*spanner.Decoder)(nil)).Elem()
+
+// Scan runs the relation and decodes every row into dest, which must be a
+// pointer to a slice. Struct elements are filled by column name like
+// spanner.Row.ToStruct, other elements are decoded from the single column of
+// the row, so Select([]string{"Name"}).Scan(ctx, &names) works with a []string.
+func (b *QueryContext) Scan(ctx context.Context, dest interface{}) error {
+	if b.err != nil {
+		return b.err
+	}
+	return b.scan(ctx, b.SQL(), b.where().Params(), dest)
+}
+
+func (b *QueryContext) QueryInto(ctx context.Context, query string, params map[string]interface{}, dest interface{}) error {
+	params, err := b.bindTenant(query, params)
+	if err != nil {
+		return err
+	}
+	return b.scan(ctx, query, params, dest)
+}
+
+func (b *QueryContext) scan(ctx context.Context, query string, params map[string]interface{}, dest interface{}) error {
+	return scanInto(dest, func(fn func(*spanner.Row) error) error {
+		return b.query(ctx, query, params, fn)
+	})
+}
+
+func (a *Aggregation) Scan(ctx context.Context, dest interface{}) error {
+	if a.qc.err != nil {
+		return a.qc.err
+	}
+
+	query, params := a.build()
+	return a.qc.scan(ctx, query, params, dest)
+}
+
+func scanInto(dest interface{}, each func(func(*spanner.Row) error) error) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return errors.Errorf("dest must be a pointer to a slice, but %T", dest)
+	}
+
+	slice := rv.Elem()
+	elemType := slice.Type().Elem()
+
+	isPtr := elemType.Kind() == reflect.Ptr
+	baseType := elemType
+	if isPtr {
+		baseType = elemType.Elem()
+	}
+	asStruct := isRowStruct(baseType)
+
+	res := reflect.MakeSlice(slice.Type(), 0, 0)
+
+	err := each(func(row *spanner.Row) error {
+		ptr := reflect.New(baseType)
+
+		if asStruct {
+			if err := row.ToStruct(ptr.Interface()); err != nil {
+				return err
+			}
+		} else {
+			if row.Size() != 1 {
+				return errors.Errorf("cannot scan %d columns into %v", row.Size(), elemType)
+			}
+			if err := row.Column(0, ptr.Interface()); err != nil {
+				return err
+			}
+		}
+
+		if isPtr {
+			res = reflect.Append(res, ptr)
+		} else {
+			res = reflect.Append(res, ptr.Elem())
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	slice.Set(res)
+	return nil
+}
+
+// isRowStruct reports whether rt is decoded field by field rather than as a
+// single column value such as time.Time or spanner.NullString.
+func isRowStruct(rt reflect.Type) bool {
+	if rt.Kind() != reflect.Struct {
+		return false
+	}
+	if reflect.PtrTo(rt).Implements(decoderType) {
+		return false
+	}
+
+	switch rt.PkgPath() {
+	case "time", "math/big", "cloud.google.com/go/civil", "cloud.google.com/go/spanner":
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,95 @@
+package blackvice
+
+// Scope is a reusable part of a query, e.g.
+//
+//	func Adults(r blackvice.Relation) blackvice.Relation {
+//		return r.Filter(blackvice.Gte("Age", 20))
+//	}
+//
+// applied with Relation.Scopes(Adults).
+type Scope func(Relation) Relation
+
+// DefaultScoper is implemented by models whose relations always apply a
+// scope until Unscoped is called. The conditions and sort keys of the
+// default scope are used; where params of the relation override those of
+// the default scope and its sort keys follow the relation's own.
+type DefaultScoper interface {
+	DefaultScope(Relation) Relation
+}
+
+// Scopes applies scopes in order. A scope must return a relation derived
+// from the one it is given, of the same model, transaction and tenant, and
+// must not call Unscoped.
+func (b *QueryContext) Scopes(scopes ...Scope) Relation {
+	r := b
+	for _, scope := range scopes {
+		next, ok := scope(r).(*QueryContext)
+		if !ok || !r.derives(next) {
+			r = r.clone()
+			if r.err == nil {
+				r.err = errInvalidScope("scope did not return a relation of the query")
+			}
+			return r
+		}
+		r = next
+	}
+	return r
+}
+
+// derives reports whether next may stand for b after a scope.
+func (b *QueryContext) derives(next *QueryContext) bool {
+	return next != nil &&
+		next.modelType == b.modelType &&
+		next.origin == b.origin &&
+		next.tenant == b.tenant &&
+		next.unscoped == b.unscoped
+}
+
+// defaults returns the relation the default scope builds on its own, or nil
+// when there is none to apply.
+func (b *QueryContext) defaults() *QueryContext {
+	if b.unscoped || b.defaultScope == nil {
+		return nil
+	}
+
+	base := baseQueryContext(b.model, b.txFn, b.origin)
+
+	d, ok := b.defaultScope(base).(*QueryContext)
+	if !ok || !base.derives(d) {
+		return nil
+	}
+	return d
+}
+
+// where returns the conditions of the relation with its scoping applied.
+func (b *QueryContext) where() WhereBuilder {
+	where := b.whereBuilder
+	if b.tenant != nil {
+		where = where.And(tenantCond{tenant: b.tenant})
+	}
+	if b.unscoped {
+		return where
+	}
+
+	if d := b.defaults(); d != nil {
+		where = d.whereBuilder.Merge(where.param).And(where.conds...)
+	}
+	if col := softDeleteColumn(b.model); col != "" {
+		where = where.And(IsNull(col))
+	}
+	return where
+}
+
+// order returns the sort keys of the relation followed by those of the
+// default scope.
+func (b *QueryContext) order() OrderBuilder {
+	order := b.orderBuilder
+	if d := b.defaults(); d != nil {
+		for _, key := range d.orderBuilder.Keys() {
+			if !order.has(key.Column) {
+				order = order.Append(key)
+			}
+		}
+	}
+	return order
+}
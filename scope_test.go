@@ -0,0 +1,63 @@
+package blackvice_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yuemori/blackvice"
+	"github.com/yuemori/blackvice/testdata"
+
+	"cloud.google.com/go/spanner"
+	"google.golang.org/grpc/codes"
+)
+
+func TestInvalidScope(t *testing.T) {
+	broken := func(r blackvice.Relation) blackvice.Relation {
+		return nil
+	}
+
+	rel := blackvice.NewQueryContext(&testdata.User{}, nil).Scopes(broken)
+	if _, err := rel.Count(context.Background()); spanner.ErrCode(err) != codes.InvalidArgument {
+		t.Fatalf("Expected InvalidArgument, but %v", err)
+	}
+}
+
+func TestDefaultScopeSQL(t *testing.T) {
+	rel := blackvice.NewQueryContext(&testdata.AuditedUser{}, nil).Where(blackvice.WhereParam{"Age": 20})
+
+	expected := "SELECT * FROM users WHERE (`Age` = @Age AND `Name` != @Name) ORDER BY `Name` ASC "
+	if sql := rel.SQL(); sql != expected {
+		t.Errorf("Expected SQL is %s, but %s", expected, sql)
+	}
+
+	expected = "SELECT * FROM users WHERE `Age` = @Age  "
+	if sql := rel.Unscoped().SQL(); sql != expected {
+		t.Errorf("Expected SQL is %s, but %s", expected, sql)
+	}
+}
+
+func TestForeignScope(t *testing.T) {
+	tdb := blackvice.New(nil).Tenant("UserId", "userId1")
+
+	scopes := []blackvice.Scope{
+		func(r blackvice.Relation) blackvice.Relation {
+			return blackvice.NewQueryContext(&testdata.User{}, nil)
+		},
+		func(r blackvice.Relation) blackvice.Relation {
+			return blackvice.NewQueryContext(&testdata.Post{}, nil)
+		},
+		func(r blackvice.Relation) blackvice.Relation {
+			return blackvice.New(nil).Relation(&testdata.Post{})
+		},
+		func(r blackvice.Relation) blackvice.Relation {
+			return r.Unscoped()
+		},
+	}
+
+	for i, scope := range scopes {
+		rel := tdb.Relation(&testdata.Post{}).Scopes(scope)
+		if _, err := rel.Count(context.Background()); spanner.ErrCode(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument for scope %d, but %v", i, err)
+		}
+	}
+}
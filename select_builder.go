@@ -10,19 +10,24 @@ func (b SelectBuilder) IsEmpty() bool {
 	return len(b.selects) == 0
 }
 
-func (b SelectBuilder) Merge(other []string) SelectBuilder {
-	selectMap := map[string]string{}
-	for _, v := range b.selects {
-		selectMap[v] = v
-	}
-
-	for _, v := range other {
-		selectMap[v] = v
+func (b SelectBuilder) has(col string) bool {
+	for _, s := range b.selects {
+		if s == col {
+			return true
+		}
 	}
+	return false
+}
 
+func (b SelectBuilder) Merge(other []string) SelectBuilder {
+	seen := map[string]bool{}
 	selects := []string{}
 
-	for _, v := range selectMap {
+	for _, v := range append(append([]string{}, b.selects...), other...) {
+		if seen[v] {
+			continue
+		}
+		seen[v] = true
 		selects = append(selects, v)
 	}
 
@@ -0,0 +1,43 @@
+package blackvice
+
+import "cloud.google.com/go/spanner"
+
+// SoftDeletable is implemented by models whose Delete sets a deletion time
+// instead of removing the row. The column must be TIMESTAMP with
+// OPTIONS (allow_commit_timestamp=true), held as spanner.NullTime or
+// time.Time. Relations skip deleted rows unless Unscoped, and ReadTx treats
+// them as not found. Deleting a deleted row fails with ReadWriteTx, while
+// Mutation overwrites its deletion time.
+type SoftDeletable interface {
+	SoftDeleteColumn() string
+}
+
+func softDeleteColumn(model Model) string {
+	if s, ok := model.(SoftDeletable); ok {
+		return s.SoftDeleteColumn()
+	}
+	return ""
+}
+
+func isSoftDeleted(model Model) bool {
+	col := softDeleteColumn(model)
+	if col == "" {
+		return false
+	}
+	return !isZeroTime(model.Params()[col])
+}
+
+// rowSoftDeleted reports whether row of model's table was soft deleted,
+// without decoding the row into a model.
+func rowSoftDeleted(row *spanner.Row, model Model) (bool, error) {
+	col := softDeleteColumn(model)
+	if col == "" {
+		return false, nil
+	}
+
+	var deletedAt spanner.NullTime
+	if err := row.ColumnByName(col, &deletedAt); err != nil {
+		return false, err
+	}
+	return deletedAt.Valid, nil
+}
@@ -10,12 +10,21 @@ import (
 type StatementBuilder struct {
 }
 
+const pendingCommitTimestamp = "PENDING_COMMIT_TIMESTAMP()"
+
 func (b StatementBuilder) Insert(target Model) spanner.Statement {
 	var values []string
 	var columns []string
+	params := target.Params()
+	createdAt, updatedAt := timestampColumns(target)
 
-	for col, _ := range target.Params() {
+	for _, col := range modelColumns(target) {
 		columns = append(columns, quote(col))
+		if col == createdAt || col == updatedAt {
+			values = append(values, pendingCommitTimestamp)
+			delete(params, col)
+			continue
+		}
 		values = append(values, placeholder(col))
 	}
 
@@ -25,28 +34,48 @@ func (b StatementBuilder) Insert(target Model) spanner.Statement {
 		strings.Join(values, ", "),
 	)
 	stmt := spanner.NewStatement(sql)
-	stmt.Params = target.Params()
+	stmt.Params = params
 
 	return stmt
 }
 
 func (b StatementBuilder) Update(target Model) spanner.Statement {
+	return b.UpdateColumns(target, modelColumns(target))
+}
+
+func (b StatementBuilder) UpdateColumns(target Model, cols []string) spanner.Statement {
+	return b.updateColumns(target, cols, target.Params()[versionColumn(target)])
+}
+
+// updateColumns builds the update of a Versioned model at the lock version
+// lock, which differs from the model's while an earlier update of the
+// transaction is not committed yet.
+func (b StatementBuilder) updateColumns(target Model, cols []string, lock interface{}) spanner.Statement {
 	var columns []string
 	params := map[string]interface{}{}
 
-	whereClause, whereParams := b.buildWherePK(target)
+	whereClause, whereParams := b.buildWhereLock(target, lock)
 	for k, v := range whereParams {
 		params[k] = v
 	}
 
-	for col, val := range target.Params() {
-		// skip primary key
-		if _, ok := target.PrimaryKeys()[col]; ok {
-			continue
+	values := target.Params()
+	version := versionColumn(target)
+	_, updatedAt := timestampColumns(target)
+	for _, col := range settableColumns(target, cols) {
+		columns = append(columns, quote(col)+"="+placeholder(col))
+		params[col] = values[col]
+	}
+
+	if version != "" {
+		if next, err := nextVersion(lock); err == nil {
+			columns = append(columns, quote(version)+"="+placeholder(version))
+			params[version] = next
 		}
+	}
 
-		columns = append(columns, quote(col)+"="+placeholder(col))
-		params[col] = val
+	if updatedAt != "" {
+		columns = append(columns, quote(updatedAt)+"="+pendingCommitTimestamp)
 	}
 
 	sql := fmt.Sprintf("UPDATE %s SET %s WHERE %s",
@@ -61,8 +90,28 @@ func (b StatementBuilder) Update(target Model) spanner.Statement {
 	return stmt
 }
 
+// Delete sets the deletion time of SoftDeletable models instead of deleting
+// the row.
 func (b StatementBuilder) Delete(target Model) spanner.Statement {
-	whereClause, params := b.buildWherePK(target)
+	return b.delete(target, target.Params()[versionColumn(target)])
+}
+
+func (b StatementBuilder) delete(target Model, lock interface{}) spanner.Statement {
+	whereClause, params := b.buildWhereLock(target, lock)
+
+	if col := softDeleteColumn(target); col != "" {
+		sql := fmt.Sprintf("UPDATE %s SET %s=%s WHERE %s AND %s IS NULL",
+			target.Table(),
+			quote(col),
+			pendingCommitTimestamp,
+			whereClause,
+			quote(col),
+		)
+		stmt := spanner.NewStatement(sql)
+		stmt.Params = params
+
+		return stmt
+	}
 
 	sql := fmt.Sprintf("DELETE FROM %s WHERE %s",
 		target.Table(),
@@ -77,15 +126,60 @@ func (b StatementBuilder) Delete(target Model) spanner.Statement {
 func (b StatementBuilder) buildWherePK(target Model) (string, map[string]interface{}) {
 	var columns []string
 	params := map[string]interface{}{}
-	for k, val := range target.PrimaryKeys() {
+	values := target.PrimaryKeys()
+	for _, k := range primaryKeyColumns(target) {
 		key := fmt.Sprintf("pk_%s", k)
 		columns = append(columns, quote(k)+"="+placeholder(key))
-		params[key] = val
+		params[key] = values[k]
 	}
 
 	return strings.Join(columns, " AND "), params
 }
 
+// buildWhereLock matches the primary key and, for Versioned models, the
+// lock version current.
+func (b StatementBuilder) buildWhereLock(target Model, current interface{}) (string, map[string]interface{}) {
+	whereClause, params := b.buildWherePK(target)
+
+	col := versionColumn(target)
+	if col == "" {
+		return whereClause, params
+	}
+
+	if isNullValue(current) {
+		return whereClause + " AND " + quote(col) + " IS NULL", params
+	}
+
+	key := fmt.Sprintf("lock_%s", col)
+	params[key] = current
+
+	return whereClause + " AND " + quote(col) + "=" + placeholder(key), params
+}
+
+// settableColumns returns the columns of cols an update sets from the model,
+// leaving out the primary key, the lock version and the timestamps.
+func settableColumns(target Model, cols []string) []string {
+	pks := target.PrimaryKeys()
+	version := versionColumn(target)
+	createdAt, updatedAt := timestampColumns(target)
+
+	var settable []string
+	for _, col := range cols {
+		if _, ok := pks[col]; ok || col == version || col == createdAt || col == updatedAt {
+			continue
+		}
+		settable = append(settable, col)
+	}
+	return settable
+}
+
+// emptyUpdate reports whether an update of cols would set no column at all,
+// which ReadWriteTx skips instead of sending an invalid statement.
+func emptyUpdate(target Model, cols []string) bool {
+	_, updatedAt := timestampColumns(target)
+	return len(settableColumns(target, cols)) == 0 && versionColumn(target) == "" && updatedAt == ""
+}
+
 func quote(str string) string {
 	return "`" + str + "`"
 }
@@ -0,0 +1,39 @@
+package blackvice_test
+
+import (
+	"testing"
+
+	"github.com/yuemori/blackvice"
+	"github.com/yuemori/blackvice/testdata"
+
+	"cloud.google.com/go/spanner"
+)
+
+func TestStatementSQL(t *testing.T) {
+	builder := blackvice.StatementBuilder{}
+	user := &testdata.User{UserId: "userId1"}
+
+	tests := []struct {
+		stmt     spanner.Statement
+		expected string
+	}{
+		{
+			builder.Insert(user),
+			"INSERT INTO users (`UserId`, `Name`, `Age`, `CreatedAt`, `UpdatedAt`) VALUES (@UserId, @Name, @Age, PENDING_COMMIT_TIMESTAMP(), PENDING_COMMIT_TIMESTAMP())",
+		},
+		{
+			builder.Update(user),
+			"UPDATE users SET `Name`=@Name, `Age`=@Age, `UpdatedAt`=PENDING_COMMIT_TIMESTAMP() WHERE `UserId`=@pk_UserId",
+		},
+		{
+			builder.Delete(user),
+			"DELETE FROM users WHERE `UserId`=@pk_UserId",
+		},
+	}
+
+	for _, tt := range tests {
+		if tt.stmt.SQL != tt.expected {
+			t.Errorf("Expected SQL is %s, but %s", tt.expected, tt.stmt.SQL)
+		}
+	}
+}
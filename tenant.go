@@ -0,0 +1,342 @@
+package blackvice
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"cloud.google.com/go/spanner"
+)
+
+// TenantParam is the query parameter bound to the tenant id in raw queries of
+// relations of a TenantDB, which must compare the tenant column with
+// @blackvice_tenant. It is always bound to the tenant of the relation.
+const TenantParam = "blackvice_tenant"
+
+type tenant struct {
+	column string
+	id     interface{}
+}
+
+type tenantCond struct {
+	tenant *tenant
+}
+
+func (c tenantCond) build(b *binder) string {
+	return quote(c.tenant.column) + " = " + b.bind(TenantParam, c.tenant.id)
+}
+
+// check returns an error unless model belongs to the tenant.
+func (t *tenant) check(model Model) error {
+	val, ok := model.Params()[t.column]
+	if !ok {
+		return errTenantViolation(fmt.Sprintf("%s has no tenant column %s", model.Table(), t.column))
+	}
+	return t.checkValue(model.Table(), val)
+}
+
+// checkKey returns an error unless key is led by the tenant id.
+func (t *tenant) checkKey(table string, key spanner.Key) error {
+	if len(key) == 0 {
+		return errTenantViolation(fmt.Sprintf("key of %s must start with %s", table, t.column))
+	}
+	return t.checkValue(table, key[0])
+}
+
+func (t *tenant) checkValue(table string, val interface{}) error {
+	v, ok := keyValue(val)
+	id, _ := keyValue(t.id)
+	if !ok || !reflect.DeepEqual(v, id) {
+		return errTenantViolation(fmt.Sprintf("%s of %s is %v, not %v", t.column, table, val, t.id))
+	}
+	return nil
+}
+
+// bindQuery binds the tenant id to the params of a raw query, which must
+// compare the tenant column with @blackvice_tenant outside of comments and
+// string literals. The check does not parse the query, so a predicate which
+// does not restrict the rows, such as one under OR, is not detected.
+func (t *tenant) bindQuery(query string, params map[string]interface{}) (map[string]interface{}, error) {
+	if !t.predicate().MatchString(stripLiterals(query)) {
+		return nil, errTenantViolation(fmt.Sprintf("query does not compare %s with @%s: %s", t.column, TenantParam, query))
+	}
+
+	bound := map[string]interface{}{}
+	for k, v := range params {
+		bound[k] = v
+	}
+	bound[TenantParam] = t.id
+
+	return bound, nil
+}
+
+// predicate matches `column` = @blackvice_tenant, with an optional table
+// qualifier and either operand first.
+func (t *tenant) predicate() *regexp.Regexp {
+	col := "(?:\\w+\\.|`\\w+`\\.)?(?:" + regexp.QuoteMeta(t.column) + "|`" + regexp.QuoteMeta(t.column) + "`)"
+	param := regexp.QuoteMeta(placeholder(TenantParam)) + "\\b"
+	return regexp.MustCompile("(?i)(?:^|[^\\w`.])" + col + "\\s*=\\s*" + param + "|" + param + "\\s*=\\s*" + col + "(?:[^\\w`]|$)")
+}
+
+// stripLiterals blanks out comments and string literals of query.
+func stripLiterals(query string) string {
+	var sb strings.Builder
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '#' || c == '-' && strings.HasPrefix(query[i:], "--"):
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+			sb.WriteByte(' ')
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return sb.String()
+			}
+			i += end + 3
+			sb.WriteByte(' ')
+		case c == '\'' || c == '"':
+			for i++; i < len(query) && query[i] != c; i++ {
+				if query[i] == '\\' {
+					i++
+				}
+			}
+			sb.WriteString("''")
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+func (b *QueryContext) withTenant(t *tenant) *QueryContext {
+	r := b.clone()
+	r.tenant = t
+	return r
+}
+
+// TenantDB is a DB restricted to the rows of one tenant, for schemas where
+// every table leads its primary key with the tenant column. Relations are
+// filtered by the tenant, and models and keys of other tenants are refused
+// with codes.PermissionDenied.
+type TenantDB struct {
+	db     *DB
+	tenant *tenant
+}
+
+func (db *DB) Tenant(column string, id interface{}) *TenantDB {
+	return &TenantDB{db: db, tenant: &tenant{column: column, id: id}}
+}
+
+func (t *TenantDB) ReadOnlyTransaction(fn func(Reader)) {
+	t.db.ReadOnlyTransaction(func(r Reader) {
+		fn(&tenantReader{r: r, tenant: t.tenant})
+	})
+}
+
+func (t *TenantDB) ReadWriteTransaction(ctx context.Context, fn func(context.Context, ReadWriter) error) error {
+	_, err := t.ReadWriteTransactionWithResult(ctx, CommitOptions{}, fn)
+	return err
+}
+
+func (t *TenantDB) ReadWriteTransactionWithResult(ctx context.Context, opts CommitOptions, fn func(context.Context, ReadWriter) error) (CommitResult, error) {
+	return t.db.ReadWriteTransactionWithResult(ctx, opts, func(ctx context.Context, rw ReadWriter) error {
+		return fn(ctx, &tenantReadWriter{tenantReader: tenantReader{r: rw, tenant: t.tenant}, rw: rw})
+	})
+}
+
+func (t *TenantDB) Relation(model Model) *QueryContext {
+	return t.db.Relation(model).withTenant(t.tenant)
+}
+
+func (t *TenantDB) Reader() Reader {
+	return &tenantReader{r: t.db.Reader(), tenant: t.tenant}
+}
+
+func (t *TenantDB) Mutator() Mutator {
+	return &tenantMutator{m: NewMutation(t.db.client), tenant: t.tenant}
+}
+
+func (t *TenantDB) Find(ctx context.Context, model Model) error {
+	return t.Reader().Find(ctx, model)
+}
+
+func (t *TenantDB) FindAll(ctx context.Context, models []Model) ([]Model, error) {
+	return t.Reader().FindAll(ctx, models)
+}
+
+func (t *TenantDB) FindByKeys(ctx context.Context, model Model, keys ...spanner.Key) ([]Model, error) {
+	return t.Reader().FindByKeys(ctx, model, keys...)
+}
+
+func (t *TenantDB) FindByKeyRange(ctx context.Context, model Model, keyRange spanner.KeyRange) ([]Model, error) {
+	return t.Reader().FindByKeyRange(ctx, model, keyRange)
+}
+
+func (t *TenantDB) ReadUsingIndex(ctx context.Context, model Model, index string, keys spanner.KeySet) ([]Model, error) {
+	return t.Reader().ReadUsingIndex(ctx, model, index, keys)
+}
+
+type tenantReader struct {
+	r      Reader
+	tenant *tenant
+}
+
+func (r *tenantReader) Relation(model Model) *QueryContext {
+	return r.r.Relation(model).withTenant(r.tenant)
+}
+
+func (r *tenantReader) Find(ctx context.Context, model Model) error {
+	if err := r.tenant.check(model); err != nil {
+		return err
+	}
+	return r.r.Find(ctx, model)
+}
+
+func (r *tenantReader) FindAll(ctx context.Context, models []Model) ([]Model, error) {
+	for _, model := range models {
+		if err := r.tenant.check(model); err != nil {
+			return nil, err
+		}
+	}
+	return r.r.FindAll(ctx, models)
+}
+
+func (r *tenantReader) FindByKeys(ctx context.Context, model Model, keys ...spanner.Key) ([]Model, error) {
+	for _, key := range keys {
+		if err := r.tenant.checkKey(model.Table(), key); err != nil {
+			return nil, err
+		}
+	}
+	return r.r.FindByKeys(ctx, model, keys...)
+}
+
+func (r *tenantReader) FindByKeyRange(ctx context.Context, model Model, keyRange spanner.KeyRange) ([]Model, error) {
+	if err := r.tenant.checkKey(model.Table(), keyRange.Start); err != nil {
+		return nil, err
+	}
+	if err := r.tenant.checkKey(model.Table(), keyRange.End); err != nil {
+		return nil, err
+	}
+	return r.r.FindByKeyRange(ctx, model, keyRange)
+}
+
+// ReadUsingIndex drops the rows of other tenants, since index keys need not
+// contain the tenant.
+func (r *tenantReader) ReadUsingIndex(ctx context.Context, model Model, index string, keys spanner.KeySet) ([]Model, error) {
+	rows, err := r.r.ReadUsingIndex(ctx, model, index, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []Model{}
+	for _, row := range rows {
+		if r.tenant.check(row) == nil {
+			res = append(res, row)
+		}
+	}
+	return res, nil
+}
+
+type tenantReadWriter struct {
+	tenantReader
+	rw ReadWriter
+}
+
+func (rw *tenantReadWriter) Insert(ctx context.Context, model Model) error {
+	if err := rw.tenant.check(model); err != nil {
+		return err
+	}
+	return rw.rw.Insert(ctx, model)
+}
+
+func (rw *tenantReadWriter) Update(ctx context.Context, model Model) error {
+	if err := rw.tenant.check(model); err != nil {
+		return err
+	}
+	return rw.rw.Update(ctx, model)
+}
+
+func (rw *tenantReadWriter) UpdateColumns(ctx context.Context, model Model, cols ...string) error {
+	if err := rw.tenant.check(model); err != nil {
+		return err
+	}
+	return rw.rw.UpdateColumns(ctx, model, cols...)
+}
+
+func (rw *tenantReadWriter) Delete(ctx context.Context, model Model) error {
+	if err := rw.tenant.check(model); err != nil {
+		return err
+	}
+	return rw.rw.Delete(ctx, model)
+}
+
+// tenantMutator fails Apply when a model of another tenant was buffered.
+type tenantMutator struct {
+	m      *Mutation
+	tenant *tenant
+	err    error
+}
+
+func (m *tenantMutator) Insert(model Model) {
+	if m.check(model) {
+		m.m.Insert(model)
+	}
+}
+
+func (m *tenantMutator) Update(model Model) {
+	if m.check(model) {
+		m.m.Update(model)
+	}
+}
+
+func (m *tenantMutator) UpdateColumns(model Model, cols ...string) {
+	if m.check(model) {
+		m.m.UpdateColumns(model, cols...)
+	}
+}
+
+func (m *tenantMutator) Delete(model Model) {
+	if m.check(model) {
+		m.m.Delete(model)
+	}
+}
+
+func (m *tenantMutator) InsertOrUpdate(model Model) {
+	if m.check(model) {
+		m.m.InsertOrUpdate(model)
+	}
+}
+
+func (m *tenantMutator) Do(ctx context.Context, fn func(context.Context, Mutator) error) error {
+	if err := m.m.within(ctx, func() error { return fn(ctx, m) }); err != nil {
+		return err
+	}
+	return m.Apply(ctx)
+}
+
+func (m *tenantMutator) Apply(ctx context.Context) error {
+	_, err := m.ApplyWithResult(ctx, CommitOptions{})
+	return err
+}
+
+// ApplyWithResult discards the buffered writes if any of them was refused.
+func (m *tenantMutator) ApplyWithResult(ctx context.Context, opts CommitOptions) (CommitResult, error) {
+	if err := m.err; err != nil {
+		m.err = nil
+		m.m.discard()
+		return CommitResult{}, err
+	}
+	return m.m.ApplyWithResult(ctx, opts)
+}
+
+func (m *tenantMutator) check(model Model) bool {
+	if m.err != nil {
+		return false
+	}
+	m.err = m.tenant.check(model)
+	return m.err == nil
+}
@@ -0,0 +1,42 @@
+package blackvice_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yuemori/blackvice"
+	"github.com/yuemori/blackvice/testdata"
+
+	"cloud.google.com/go/spanner"
+	"google.golang.org/grpc/codes"
+)
+
+func TestTenantQuery(t *testing.T) {
+	tdb := blackvice.New(nil).Tenant("UserId", "userId1")
+
+	queries := []string{
+		"SELECT * FROM posts WHERE @blackvice_tenant IS NOT NULL",
+		"SELECT * FROM posts WHERE UserId = @blackvice_tenant_x",
+		"SELECT * FROM posts WHERE OtherUserId = @blackvice_tenant",
+		"SELECT * FROM posts -- WHERE UserId = @blackvice_tenant",
+		"SELECT * FROM posts /* UserId = @blackvice_tenant */",
+		"SELECT * FROM posts WHERE Title = 'UserId = @blackvice_tenant'",
+	}
+
+	for _, query := range queries {
+		_, err := tdb.Relation(&testdata.Post{}).Query(context.Background(), query, nil)
+		if spanner.ErrCode(err) != codes.PermissionDenied {
+			t.Errorf("Expected PermissionDenied for %q, but %v", query, err)
+		}
+	}
+}
+
+func TestTenantSQL(t *testing.T) {
+	rel := blackvice.New(nil).Tenant("UserId", "userId1").Relation(&testdata.Post{}).
+		Where(blackvice.WhereParam{"blackvice_tenant": "x"})
+
+	expected := "SELECT * FROM posts WHERE (`blackvice_tenant` = @blackvice_tenant AND `UserId` = @blackvice_tenant_1 AND `DeletedAt` IS NULL)  "
+	if sql := rel.SQL(); sql != expected {
+		t.Errorf("Expected SQL is %s, but %s", expected, sql)
+	}
+}
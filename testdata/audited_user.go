@@ -0,0 +1,58 @@
+package testdata
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/yuemori/blackvice"
+)
+
+var ErrEmptyName = errors.New("name must not be empty")
+
+// AuditedUser is a User with lifecycle hooks recording its events, whose
+// relations skip users without a name.
+type AuditedUser struct {
+	User
+	Events []string `spanner:"-"`
+}
+
+func (u *AuditedUser) BeforeInsert(ctx context.Context) error {
+	return u.normalize()
+}
+
+func (u *AuditedUser) AfterInsert(ctx context.Context) error {
+	u.Events = append(u.Events, "insert")
+	return nil
+}
+
+func (u *AuditedUser) BeforeUpdate(ctx context.Context) error {
+	return u.normalize()
+}
+
+func (u *AuditedUser) AfterUpdate(ctx context.Context) error {
+	u.Events = append(u.Events, "update")
+	return nil
+}
+
+func (u *AuditedUser) AfterDelete(ctx context.Context) error {
+	u.Events = append(u.Events, "delete")
+	return nil
+}
+
+func (u *AuditedUser) AfterFind(ctx context.Context) error {
+	u.Events = append(u.Events, "find")
+	return nil
+}
+
+func (u *AuditedUser) DefaultScope(r blackvice.Relation) blackvice.Relation {
+	return r.Filter(blackvice.Ne("Name", "")).OrderBy(blackvice.Asc("Name"))
+}
+
+func (u *AuditedUser) normalize() error {
+	u.Name = strings.TrimSpace(u.Name)
+	if u.Name == "" {
+		return ErrEmptyName
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package testdata
+
+import (
+	"cloud.google.com/go/spanner"
+)
+
+// Note is soft deletable without a lock version, and leaves the key order to
+// SpannerKey.
+type Note struct {
+	Zone      string
+	Id        int64
+	Text      string
+	DeletedAt spanner.NullTime
+}
+
+func (n *Note) Table() string {
+	return "notes"
+}
+
+func (n *Note) Params() map[string]interface{} {
+	return map[string]interface{}{
+		"Zone":      n.Zone,
+		"Id":        n.Id,
+		"Text":      n.Text,
+		"DeletedAt": n.DeletedAt,
+	}
+}
+
+func (n *Note) Columns() []string {
+	return []string{"Zone", "Id", "Text", "DeletedAt"}
+}
+
+func (n *Note) SpannerKey() spanner.Key {
+	return spanner.Key{n.Zone, n.Id}
+}
+
+func (n *Note) PrimaryKeys() map[string]interface{} {
+	return map[string]interface{}{
+		"Zone": n.Zone,
+		"Id":   n.Id,
+	}
+}
+
+func (n *Note) SoftDeleteColumn() string {
+	return "DeletedAt"
+}
@@ -0,0 +1,27 @@
+package testdata
+
+import (
+	"github.com/yuemori/blackvice"
+
+	"cloud.google.com/go/spanner"
+)
+
+type Post struct {
+	blackvice.AutoModel[Post] `spanner:"-" blackvice:"table=posts"`
+	blackvice.ChangeTracker   `spanner:"-"`
+
+	UserId    string `blackvice:"pk,order=1"`
+	PostId    int64  `blackvice:"pk,order=2"`
+	Title     string `validate:"required,max=256"`
+	Body      spanner.NullString
+	Version   int64            `blackvice:"version"`
+	DeletedAt spanner.NullTime `blackvice:"deleted_at"`
+
+	User *User `spanner:"-"`
+}
+
+func (p *Post) Associations() []blackvice.Association {
+	return []blackvice.Association{
+		blackvice.BelongsTo("User", &User{}, "UserId", "UserId"),
+	}
+}
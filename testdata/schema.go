@@ -2,6 +2,9 @@ package testdata
 
 var (
 	CreateTableStatements = []string{
-		"CREATE TABLE users (`UserId` STRING(36) NOT NULL, `Name` STRING(36), `Age` INT64, `CreatedAt` TIMESTAMP, `UpdatedAt` TIMESTAMP) PRIMARY KEY (`UserId`)",
+		"CREATE TABLE users (`UserId` STRING(36) NOT NULL, `Name` STRING(36), `Age` INT64, `CreatedAt` TIMESTAMP OPTIONS (allow_commit_timestamp=true), `UpdatedAt` TIMESTAMP OPTIONS (allow_commit_timestamp=true)) PRIMARY KEY (`UserId`)",
+		"CREATE INDEX UsersByName ON users (`Name`)",
+		"CREATE TABLE posts (`UserId` STRING(36) NOT NULL, `PostId` INT64 NOT NULL, `Title` STRING(256) NOT NULL, `Body` STRING(MAX), `Version` INT64 NOT NULL, `DeletedAt` TIMESTAMP OPTIONS (allow_commit_timestamp=true)) PRIMARY KEY (`UserId`, `PostId`), INTERLEAVE IN PARENT users ON DELETE CASCADE",
+		"CREATE TABLE notes (`Zone` STRING(36) NOT NULL, `Id` INT64 NOT NULL, `Text` STRING(MAX), `DeletedAt` TIMESTAMP OPTIONS (allow_commit_timestamp=true)) PRIMARY KEY (`Zone`, `Id`)",
 	}
 )
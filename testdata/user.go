@@ -3,15 +3,19 @@ package testdata
 import (
 	"time"
 
+	"github.com/yuemori/blackvice"
+
 	"cloud.google.com/go/spanner"
 )
 
 type User struct {
-	UserId    string
-	Name      string
-	Age       int64
+	UserId    string `validate:"required,max=36"`
+	Name      string `validate:"max=36"`
+	Age       int64  `validate:"min=0"`
 	CreatedAt time.Time
 	UpdatedAt time.Time
+
+	Posts []*Post `spanner:"-"`
 }
 
 func (u *User) Table() string {
@@ -28,6 +32,10 @@ func (u *User) Params() map[string]interface{} {
 	}
 }
 
+func (u *User) Columns() []string {
+	return []string{"UserId", "Name", "Age", "CreatedAt", "UpdatedAt"}
+}
+
 func (u *User) SpannerKey() spanner.Key {
 	return spanner.Key{u.UserId}
 }
@@ -37,3 +45,17 @@ func (u *User) PrimaryKeys() map[string]interface{} {
 		"UserId": u.UserId,
 	}
 }
+
+func (u *User) PrimaryKeyColumns() []string {
+	return []string{"UserId"}
+}
+
+func (u *User) TimestampColumns() (string, string) {
+	return "CreatedAt", "UpdatedAt"
+}
+
+func (u *User) Associations() []blackvice.Association {
+	return []blackvice.Association{
+		blackvice.HasMany("Posts", &Post{}, "UserId", "UserId"),
+	}
+}
@@ -0,0 +1,65 @@
+package blackvice
+
+import (
+	"time"
+
+	"cloud.google.com/go/spanner"
+)
+
+// Timestamped is implemented by models whose creation and update times are
+// set to the commit timestamp on write. Either column may be empty, and the
+// columns must be TIMESTAMP with OPTIONS (allow_commit_timestamp=true).
+// The fields may be time.Time or spanner.NullTime and receive the actual
+// commit timestamp once the write is committed.
+type Timestamped interface {
+	TimestampColumns() (createdAt, updatedAt string)
+}
+
+func timestampColumns(model Model) (createdAt, updatedAt string) {
+	if t, ok := model.(Timestamped); ok {
+		return t.TimestampColumns()
+	}
+	return "", ""
+}
+
+// stamp records the columns of a model written with the commit timestamp.
+type stamp struct {
+	model Model
+	cols  []string
+}
+
+// stampValues replaces the values of timestamp columns by
+// spanner.CommitTimestamp. The creation time is kept unless overwrite is set
+// or it is zero, so InsertOrUpdate does not reset it on existing rows.
+func stampValues(model Model, cols []string, values []interface{}, overwrite bool) ([]interface{}, stamp) {
+	createdAt, updatedAt := timestampColumns(model)
+	s := stamp{model: model}
+
+	for i, col := range cols {
+		if col != updatedAt && (col != createdAt || !overwrite && !isZeroTime(values[i])) {
+			continue
+		}
+		values[i] = spanner.CommitTimestamp
+		s.cols = append(s.cols, col)
+	}
+
+	return values, s
+}
+
+func isZeroTime(val interface{}) bool {
+	switch v := val.(type) {
+	case time.Time:
+		return v.IsZero()
+	case spanner.NullTime:
+		return !v.Valid || v.Time.IsZero()
+	}
+	return val == nil
+}
+
+// setTimestamp stores ts into a time.Time or spanner.NullTime field.
+func setTimestamp(model Model, col string, ts time.Time) error {
+	if err := setColumn(model, col, ts); err != nil {
+		return setColumn(model, col, spanner.NullTime{Time: ts, Valid: true})
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package blackvice
+
+import (
+	"context"
+	"reflect"
+
+	"cloud.google.com/go/spanner"
+)
+
+// ChangeTracker makes Update write only the columns changed since the model
+// was loaded or last written. Embed it in a model to opt in:
+//
+//	type User struct {
+//		blackvice.ChangeTracker `spanner:"-"`
+//		...
+//	}
+type ChangeTracker struct {
+	original map[string]interface{}
+}
+
+func (t *ChangeTracker) trackOriginal(params map[string]interface{}) {
+	t.original = params
+}
+
+func (t *ChangeTracker) originalParams() map[string]interface{} {
+	return t.original
+}
+
+type tracker interface {
+	trackOriginal(map[string]interface{})
+	originalParams() map[string]interface{}
+}
+
+func snapshot(model Model) {
+	if t, ok := model.(tracker); ok {
+		t.trackOriginal(model.Params())
+	}
+}
+
+// changedColumns returns the non primary key columns to write for an update,
+// which are every column unless the model tracks changes.
+func changedColumns(model Model) []string {
+	pks := model.PrimaryKeys()
+	params := model.Params()
+
+	var original map[string]interface{}
+	if t, ok := model.(tracker); ok {
+		original = t.originalParams()
+	}
+
+	var cols []string
+	for _, col := range modelColumns(model) {
+		if _, ok := pks[col]; ok {
+			continue
+		}
+		if original != nil {
+			if val, ok := original[col]; ok && reflect.DeepEqual(val, params[col]) {
+				continue
+			}
+		}
+		cols = append(cols, col)
+	}
+	return cols
+}
+
+func decodeModel(ctx context.Context, row *spanner.Row, model Model) error {
+	if err := row.ToStruct(model); err != nil {
+		return err
+	}
+	return afterFind(ctx, model)
+}
+
+func afterFind(ctx context.Context, model Model) error {
+	snapshot(model)
+
+	if h, ok := model.(AfterFinder); ok {
+		return h.AfterFind(ctx)
+	}
+	return nil
+}
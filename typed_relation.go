@@ -0,0 +1,195 @@
+package blackvice
+
+import (
+	"context"
+
+	"cloud.google.com/go/spanner"
+)
+
+type ModelPtr[T any] interface {
+	*T
+	Model
+}
+
+type relationer interface {
+	Relation(Model) *QueryContext
+}
+
+type TypedRelation[T any, PT ModelPtr[T]] struct {
+	qc *QueryContext
+}
+
+// Query starts a Relation whose results are typed as *T, e.g.
+// blackvice.Query[testdata.User](db).Where(...).All(ctx).
+func Query[T any, PT ModelPtr[T]](r relationer) *TypedRelation[T, PT] {
+	return &TypedRelation[T, PT]{qc: r.Relation(PT(new(T)))}
+}
+
+func (r *TypedRelation[T, PT]) with(rel Relation) *TypedRelation[T, PT] {
+	return &TypedRelation[T, PT]{qc: rel.(*QueryContext)}
+}
+
+func (r *TypedRelation[T, PT]) ForceIndex(index string) *TypedRelation[T, PT] {
+	return r.with(r.qc.ForceIndex(index))
+}
+
+func (r *TypedRelation[T, PT]) Select(selects []string) *TypedRelation[T, PT] {
+	return r.with(r.qc.Select(selects))
+}
+
+func (r *TypedRelation[T, PT]) Where(param WhereParam) *TypedRelation[T, PT] {
+	return r.with(r.qc.Where(param))
+}
+
+func (r *TypedRelation[T, PT]) Filter(conds ...Condition) *TypedRelation[T, PT] {
+	return r.with(r.qc.Filter(conds...))
+}
+
+func (r *TypedRelation[T, PT]) Order(param OrderParam) *TypedRelation[T, PT] {
+	return r.with(r.qc.Order(param))
+}
+
+func (r *TypedRelation[T, PT]) OrderBy(keys ...OrderKey) *TypedRelation[T, PT] {
+	return r.with(r.qc.OrderBy(keys...))
+}
+
+func (r *TypedRelation[T, PT]) Limit(limit int) *TypedRelation[T, PT] {
+	return r.with(r.qc.Limit(limit))
+}
+
+func (r *TypedRelation[T, PT]) Offset(offset int) *TypedRelation[T, PT] {
+	return r.with(r.qc.Offset(offset))
+}
+
+func (r *TypedRelation[T, PT]) Preload(names ...string) *TypedRelation[T, PT] {
+	return r.with(r.qc.Preload(names...))
+}
+
+func (r *TypedRelation[T, PT]) Scopes(scopes ...Scope) *TypedRelation[T, PT] {
+	return r.with(r.qc.Scopes(scopes...))
+}
+
+func (r *TypedRelation[T, PT]) Unscoped() *TypedRelation[T, PT] {
+	return r.with(r.qc.Unscoped())
+}
+
+func (r *TypedRelation[T, PT]) SQL() string {
+	return r.qc.SQL()
+}
+
+func (r *TypedRelation[T, PT]) Table() string {
+	return r.qc.Table()
+}
+
+func (r *TypedRelation[T, PT]) All(ctx context.Context) ([]*T, error) {
+	if r.qc.err != nil {
+		return nil, r.qc.err
+	}
+	rows, err := r.query(ctx, r.qc.SQL(), r.qc.where().Params())
+	if err != nil {
+		return nil, err
+	}
+
+	models := make([]Model, 0, len(rows))
+	for _, row := range rows {
+		models = append(models, PT(row))
+	}
+	if err := r.qc.preload(ctx, models); err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+func (r *TypedRelation[T, PT]) FindOne(ctx context.Context) (*T, error) {
+	q := r.Limit(1)
+
+	rows, err := q.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errRowNotFound(q.Table(), q.SQL())
+	}
+
+	return rows[0], nil
+}
+
+func (r *TypedRelation[T, PT]) Count(ctx context.Context) (int64, error) {
+	return r.qc.Count(ctx)
+}
+
+func (r *TypedRelation[T, PT]) Exists(ctx context.Context) (bool, error) {
+	return r.qc.Exists(ctx)
+}
+
+func (r *TypedRelation[T, PT]) Scan(ctx context.Context, dest interface{}) error {
+	return r.qc.Scan(ctx, dest)
+}
+
+func (r *TypedRelation[T, PT]) Aggregate(aggs ...Aggregate) *Aggregation {
+	return r.qc.Aggregate(aggs...)
+}
+
+func (r *TypedRelation[T, PT]) Query(ctx context.Context, query string, params map[string]interface{}) ([]*T, error) {
+	params, err := r.qc.bindTenant(query, params)
+	if err != nil {
+		return nil, err
+	}
+	return r.query(ctx, query, params)
+}
+
+func (r *TypedRelation[T, PT]) query(ctx context.Context, query string, params map[string]interface{}) ([]*T, error) {
+	res := []*T{}
+
+	err := r.qc.query(ctx, query, params, func(row *spanner.Row) error {
+		val := new(T)
+		if err := decodeModel(ctx, row, PT(val)); err != nil {
+			return err
+		}
+		res = append(res, val)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *TypedRelation[T, PT]) Each(ctx context.Context, fn func(*T) error) error {
+	if r.qc.err != nil {
+		return r.qc.err
+	}
+
+	return r.qc.query(ctx, r.qc.SQL(), r.qc.where().Params(), func(row *spanner.Row) error {
+		val := new(T)
+		if err := decodeModel(ctx, row, PT(val)); err != nil {
+			return err
+		}
+		return fn(val)
+	})
+}
+
+func (r *TypedRelation[T, PT]) Paginate(ctx context.Context, cursor string, pageSize int) ([]*T, string, error) {
+	q, keys, err := r.qc.page(cursor, pageSize)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rows, err := r.with(q).All(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(rows) <= pageSize {
+		return rows, "", nil
+	}
+
+	rows = rows[:pageSize]
+	next, err := r.qc.encodeCursor(keys, PT(rows[pageSize-1]))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return rows, next, nil
+}
@@ -0,0 +1,193 @@
+package blackvice
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"cloud.google.com/go/spanner"
+	"github.com/pkg/errors"
+)
+
+// Validator is implemented by models with checks beyond the validate tags.
+// Returning a *ValidationError merges its fields with those of the tags.
+type Validator interface {
+	Validate() error
+}
+
+// Validate checks the validate tags of model's fields and then its Validator,
+// returning a *ValidationError listing every failing field. Writes of
+// ReadWriteTx and Mutation call it after the Before hooks. The rules are:
+//
+//	required      the value is not zero or NULL
+//	max=N, min=N  the length of a string (in characters) or slice, or the
+//	              bounds of a number
+//	oneof=a b c   the value is one of the space separated values
+func Validate(model Model) error {
+	invalid := &ValidationError{Table: model.Table()}
+
+	rv := reflect.ValueOf(model)
+	if rv.Kind() == reflect.Ptr && rv.Elem().Kind() == reflect.Struct {
+		if err := validateStruct(rv.Elem(), invalid); err != nil {
+			return err
+		}
+	}
+
+	if v, ok := model.(Validator); ok {
+		if err := v.Validate(); err != nil {
+			var other *ValidationError
+			if !errors.As(err, &other) {
+				return err
+			}
+			invalid.Fields = append(invalid.Fields, other.Fields...)
+		}
+	}
+
+	if len(invalid.Fields) > 0 {
+		return invalid
+	}
+	return nil
+}
+
+func validateStruct(rv reflect.Value, invalid *ValidationError) error {
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		// unexported fields cannot be read, even embedded ones
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		name := sf.Tag.Get("spanner")
+		if name == "-" {
+			continue
+		}
+
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct && name == "" {
+			if err := validateStruct(rv.Field(i), invalid); err != nil {
+				return err
+			}
+			continue
+		}
+
+		tag := sf.Tag.Get("validate")
+		if tag == "" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+
+		for _, rule := range strings.Split(tag, ",") {
+			kv := strings.SplitN(strings.TrimSpace(rule), "=", 2)
+			arg := ""
+			if len(kv) == 2 {
+				arg = kv[1]
+			}
+
+			msg, err := checkRule(rv.Field(i), kv[0], arg)
+			if err != nil {
+				return errors.Wrapf(err, "%v.%s", rt, sf.Name)
+			}
+			if msg != "" {
+				invalid.Fields = append(invalid.Fields, FieldError{Column: name, Rule: kv[0], Message: msg})
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkRule returns the message of a failing rule, or an error for a
+// malformed one.
+func checkRule(field reflect.Value, rule, arg string) (string, error) {
+	val, null := fieldValue(field)
+
+	switch rule {
+	case "":
+		return "", nil
+	case "required":
+		if null || val.IsZero() {
+			return "is required", nil
+		}
+		return "", nil
+	case "max", "min":
+		limit, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return "", errors.Errorf("invalid %s %q", rule, arg)
+		}
+		if null {
+			return "", nil
+		}
+		n, isLen, ok := measure(val)
+		if !ok {
+			return "", errors.Errorf("%s is not supported for %v", rule, val.Type())
+		}
+		if rule == "max" && n > limit || rule == "min" && n < limit {
+			return boundMessage(rule, arg, isLen), nil
+		}
+		return "", nil
+	case "oneof":
+		if null {
+			return "", nil
+		}
+		s := fmt.Sprint(val.Interface())
+		for _, opt := range strings.Fields(arg) {
+			if s == opt {
+				return "", nil
+			}
+		}
+		return fmt.Sprintf("must be one of %s", strings.Join(strings.Fields(arg), ", ")), nil
+	}
+
+	return "", errors.Errorf("unknown validation rule %q", rule)
+}
+
+// fieldValue unwraps spanner.Null* values, reporting whether they are NULL.
+func fieldValue(field reflect.Value) (reflect.Value, bool) {
+	if !field.CanInterface() {
+		return field, false
+	}
+	n, ok := field.Interface().(spanner.NullableValue)
+	if !ok {
+		return field, false
+	}
+	if n.IsNull() {
+		return field, true
+	}
+	if field.Kind() == reflect.Struct && field.NumField() > 0 {
+		return field.Field(0), false
+	}
+	return field, false
+}
+
+func measure(val reflect.Value) (float64, bool, bool) {
+	switch val.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(val.String())), true, true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(val.Len()), true, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(val.Int()), false, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(val.Uint()), false, true
+	case reflect.Float32, reflect.Float64:
+		return val.Float(), false, true
+	}
+	return 0, false, false
+}
+
+func boundMessage(rule, arg string, isLen bool) string {
+	switch {
+	case rule == "max" && isLen:
+		return fmt.Sprintf("length must be at most %s", arg)
+	case rule == "max":
+		return fmt.Sprintf("must be at most %s", arg)
+	case isLen:
+		return fmt.Sprintf("length must be at least %s", arg)
+	}
+	return fmt.Sprintf("must be at least %s", arg)
+}
@@ -0,0 +1,33 @@
+package blackvice_test
+
+import (
+	"testing"
+
+	"github.com/yuemori/blackvice"
+)
+
+type audit struct {
+	Note string `validate:"required"`
+}
+
+type level int
+
+type embeddingUnexported struct {
+	blackvice.AutoModel[embeddingUnexported] `spanner:"-" blackvice:"table=embedding"`
+	audit
+	level `validate:"min=1"`
+
+	Id   string `blackvice:"pk"`
+	Name string `validate:"required"`
+}
+
+func TestValidateUnexportedEmbedded(t *testing.T) {
+	err := blackvice.Validate(&embeddingUnexported{Id: "id"})
+	invalid, ok := err.(*blackvice.ValidationError)
+	if !ok {
+		t.Fatalf("Expected ValidationError, but %v", err)
+	}
+	if len(invalid.Fields) != 1 || invalid.Fields[0].Column != "Name" {
+		t.Fatalf("Only exported fields must be validated: %v", invalid.Fields)
+	}
+}
@@ -0,0 +1,104 @@
+package blackvice
+
+import (
+	"reflect"
+	"strings"
+
+	"cloud.google.com/go/spanner"
+	"github.com/pkg/errors"
+)
+
+// Versioned is implemented by models with a lock version column. Update and
+// Delete of ReadWriteTx only match the row when its version is unchanged and
+// return ErrStaleObject otherwise; Update also increments the version, which
+// the model receives once committed. Mutation only inserts Versioned models,
+// since mutations cannot check the version.
+// The column must be an INT64 held as int64, int or spanner.NullInt64.
+type Versioned interface {
+	VersionColumn() string
+}
+
+func versionColumn(model Model) string {
+	if v, ok := model.(Versioned); ok {
+		return v.VersionColumn()
+	}
+	return ""
+}
+
+// nextVersion returns the value following the current version, where a NULL
+// version counts as 0.
+func nextVersion(current interface{}) (interface{}, error) {
+	switch v := current.(type) {
+	case int64:
+		return v + 1, nil
+	case int:
+		return v + 1, nil
+	case spanner.NullInt64:
+		return spanner.NullInt64{Int64: v.Int64 + 1, Valid: true}, nil
+	}
+	return nil, errors.Errorf("unsupported version type %T", current)
+}
+
+func isNullValue(val interface{}) bool {
+	if val == nil {
+		return true
+	}
+	if v, ok := val.(spanner.NullableValue); ok {
+		return v.IsNull()
+	}
+	return false
+}
+
+// setColumn stores val into the field of model mapped to col, matching the
+// spanner tag or the field name like spanner.Row.ToStruct.
+func setColumn(model Model, col string, val interface{}) error {
+	rv := reflect.ValueOf(model)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return errors.Errorf("cannot set %s of %T", col, model)
+	}
+
+	field, ok := columnField(rv.Elem(), col)
+	if !ok || !field.CanSet() {
+		return errors.Errorf("%T has no field for column %s", model, col)
+	}
+
+	v := reflect.ValueOf(val)
+	if !v.Type().ConvertibleTo(field.Type()) {
+		return errors.Errorf("cannot set %T to column %s of %T", val, col, model)
+	}
+	field.Set(v.Convert(field.Type()))
+
+	return nil
+}
+
+func columnField(rv reflect.Value, col string) (reflect.Value, bool) {
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous {
+			continue
+		}
+
+		name := sf.Tag.Get("spanner")
+		if name == "-" {
+			continue
+		}
+
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct && name == "" {
+			if field, ok := columnField(rv.Field(i), col); ok {
+				return field, true
+			}
+			continue
+		}
+
+		if name == "" {
+			name = sf.Name
+		}
+		if strings.EqualFold(name, col) {
+			return rv.Field(i), true
+		}
+	}
+
+	return reflect.Value{}, false
+}
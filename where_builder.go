@@ -2,15 +2,15 @@ package blackvice
 
 import (
 	"fmt"
-	"strings"
 )
 
 type WhereBuilder struct {
 	param WhereParam
+	conds []Condition
 }
 
 func (b WhereBuilder) IsEmpty() bool {
-	return len(b.param) == 0
+	return len(b.param) == 0 && len(b.conds) == 0
 }
 
 func (b WhereBuilder) Get(key string) (interface{}, bool) {
@@ -32,23 +32,48 @@ func (b WhereBuilder) Merge(other WhereParam) WhereBuilder {
 
 	return WhereBuilder{
 		param: param,
+		conds: b.conds,
 	}
 }
 
-func (b WhereBuilder) Build() string {
-	if len(b.param) == 0 {
-		return ""
-	}
-	param := []string{}
+func (b WhereBuilder) And(conds ...Condition) WhereBuilder {
+	merged := make([]Condition, 0, len(b.conds)+len(conds))
+	merged = append(merged, b.conds...)
+	merged = append(merged, conds...)
 
-	// TODO: use reflection
-	for col := range b.param {
-		param = append(param, quote(col)+"="+placeholder(col))
+	return WhereBuilder{
+		param: b.param,
+		conds: merged,
 	}
+}
 
-	return fmt.Sprintf("WHERE %s", strings.Join(param, " AND "))
+func (b WhereBuilder) Build() string {
+	clause, _ := b.build()
+	return clause
 }
 
 func (b WhereBuilder) Params() WhereParam {
-	return b.param
+	_, params := b.build()
+	return params
+}
+
+func (b WhereBuilder) build() (string, WhereParam) {
+	if b.IsEmpty() {
+		return "", WhereParam{}
+	}
+
+	binder := newBinder()
+	expr := b.expr(binder)
+
+	return fmt.Sprintf("WHERE %s", expr), binder.params
+}
+
+func (b WhereBuilder) expr(binder *binder) string {
+	conds := []Condition{}
+	if len(b.param) != 0 {
+		conds = append(conds, b.param)
+	}
+	conds = append(conds, b.conds...)
+
+	return And(conds...).build(binder)
 }